@@ -52,17 +52,42 @@ func HandleAcceptAnswer(app core.App) func(e *core.RequestEvent) error {
 
 		// 기존 채택 해제 + 새 채택을 트랜잭션으로 처리
 		err = app.RunInTransaction(func(txApp core.App) error {
+			// 이미 채택된 답변이면 변경 없음
+			if answer.GetBool("is_accepted") {
+				return nil
+			}
+
+			previous, dbErr := txApp.FindRecordsByFilter("answers",
+				"question = {:qid} && is_accepted = true",
+				"", 0, 0,
+				dbx.Params{"qid": questionId},
+			)
+			if dbErr != nil {
+				return dbErr
+			}
+
 			// 같은 질문의 기존 채택된 답변 해제
-			_, dbErr := txApp.DB().NewQuery(
+			_, dbErr = txApp.DB().NewQuery(
 				"UPDATE answers SET is_accepted = false WHERE question = {:qid} AND is_accepted = true",
 			).Bind(dbx.Params{"qid": questionId}).Execute()
 			if dbErr != nil {
 				return dbErr
 			}
 
+			// 채택 해제된 답변의 평판 점수 회수
+			for _, prev := range previous {
+				if err := awardReputation(txApp, prev.GetString("author"), -pointsAnswerAccepted, "answer_accepted", prev.Id, "answer", userId); err != nil {
+					return err
+				}
+			}
+
 			// 새 답변 채택
 			answer.Set("is_accepted", true)
-			return txApp.Save(answer)
+			if err := txApp.Save(answer); err != nil {
+				return err
+			}
+
+			return awardReputation(txApp, answerAuthorId, pointsAnswerAccepted, "answer_accepted", answer.Id, "answer", userId)
 		})
 
 		if err != nil {
@@ -6,6 +6,7 @@ import (
 
 	"github.com/pocketbase/pocketbase/apis"
 	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
 	"github.com/pocketbase/dbx"
 )
 
@@ -115,6 +116,20 @@ type AdminCatalogItem struct {
 	Created        string `db:"created" json:"created"`
 }
 
+type AdminPushItem struct {
+	ID             string `db:"id" json:"id"`
+	User           string `db:"user" json:"user"`
+	UserName       string `db:"user_name" json:"user_name"`
+	Kind           string `db:"kind" json:"kind"`
+	NotificationID string `db:"notification_id" json:"notification_id"`
+	Title          string `db:"title" json:"title"`
+	Status         string `db:"status" json:"status"`
+	Attempts       int    `db:"attempts" json:"attempts"`
+	LastError      string `db:"last_error" json:"last_error"`
+	NextAttemptAt  string `db:"next_attempt_at" json:"next_attempt_at"`
+	Created        string `db:"created" json:"created"`
+}
+
 // ============================================================
 // 1. HandleAdminStatsOverview
 // GET /api/admin/stats/overview
@@ -964,3 +979,111 @@ func HandleAdminApproveCatalog(app core.App) func(e *core.RequestEvent) error {
 		})
 	}
 }
+
+// ============================================================
+// 18. HandleAdminGetPushFailures
+// GET /api/admin/push/failures?status=dead&page=1&perPage=20
+// ============================================================
+
+func HandleAdminGetPushFailures(app core.App) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		q := e.Request.URL.Query()
+
+		page, _ := strconv.Atoi(q.Get("page"))
+		if page < 1 {
+			page = 1
+		}
+		perPage, _ := strconv.Atoi(q.Get("perPage"))
+		if perPage < 1 || perPage > 100 {
+			perPage = 20
+		}
+		offset := (page - 1) * perPage
+
+		// dead: 재시도 포기, retrying: 실패 후 재시도 대기 중
+		var whereClause string
+		switch q.Get("status") {
+		case "", "dead":
+			whereClause = "WHERE o.status = 'dead'"
+		case "retrying":
+			whereClause = "WHERE o.status = 'pending' AND o.attempts > 0"
+		default:
+			return apis.NewBadRequestError("Status must be 'dead' or 'retrying'", nil)
+		}
+
+		params := dbx.Params{
+			"limit":  perPage,
+			"offset": offset,
+		}
+
+		var items []AdminPushItem
+		err := app.DB().NewQuery(`
+			SELECT
+				o.id, o.user,
+				COALESCE(u.name, '') as user_name,
+				o.kind,
+				COALESCE(o.notification_id, '') as notification_id,
+				COALESCE(o.title, '') as title,
+				o.status, o.attempts,
+				COALESCE(o.last_error, '') as last_error,
+				COALESCE(o.next_attempt_at, '') as next_attempt_at,
+				o.created
+			FROM push_outbox o
+			LEFT JOIN users u ON u.id = o.user
+			` + whereClause + `
+			ORDER BY o.updated DESC
+			LIMIT {:limit} OFFSET {:offset}
+		`).Bind(params).All(&items)
+
+		if err != nil {
+			return apis.NewApiError(http.StatusInternalServerError, "Failed to fetch push failures", err)
+		}
+
+		var total int
+		_ = app.DB().NewQuery("SELECT COUNT(*) FROM push_outbox o " + whereClause).Row(&total)
+
+		if items == nil {
+			items = []AdminPushItem{}
+		}
+
+		return e.JSON(http.StatusOK, map[string]any{
+			"items":      items,
+			"page":       page,
+			"perPage":    perPage,
+			"totalItems": total,
+			"totalPages": (total + perPage - 1) / perPage,
+		})
+	}
+}
+
+// ============================================================
+// 19. HandleAdminRetryPush
+// POST /api/admin/push/{id}/retry
+// ============================================================
+
+func HandleAdminRetryPush(app core.App) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		itemId := e.Request.PathValue("id")
+
+		record, err := app.FindRecordById("push_outbox", itemId)
+		if err != nil {
+			return apis.NewNotFoundError("Push not found", err)
+		}
+
+		if record.GetString("status") == "sent" {
+			return apis.NewBadRequestError("Push was already sent", nil)
+		}
+
+		record.Set("status", "pending")
+		record.Set("attempts", 0)
+		record.Set("next_attempt_at", types.NowDateTime())
+		if err := app.Save(record); err != nil {
+			return apis.NewApiError(http.StatusInternalServerError, "Failed to retry push", err)
+		}
+
+		return e.JSON(http.StatusOK, map[string]any{
+			"message": "Push queued for retry",
+			"id":      itemId,
+			"status":  "pending",
+		})
+	}
+}
@@ -19,6 +19,7 @@ type CommentRow struct {
 	ParentCommentID string `db:"parent_comment" json:"parent_comment"`
 	Created         string `db:"created" json:"created"`
 	Updated         string `db:"updated" json:"updated"`
+	EditedAt        string `db:"edited_at" json:"edited_at"`
 	IsLiked         int    `db:"is_liked" json:"-"`
 	Depth           int    `db:"depth" json:"-"`
 }
@@ -33,6 +34,7 @@ type CommentNode struct {
 	ParentCommentID string        `json:"parent_comment,omitempty"`
 	Created         string        `json:"created"`
 	Updated         string        `json:"updated"`
+	EditedAt        string        `json:"edited_at"`
 	IsLiked         bool          `json:"is_liked"`
 	Replies         []CommentNode `json:"replies"`
 }
@@ -51,12 +53,14 @@ func HandleGetCommentTree(app core.App) func(e *core.RequestEvent) error {
 		err := app.DB().NewQuery(`
 			WITH RECURSIVE comment_tree AS (
 				SELECT id, post, author, author_name, content,
-					   like_count, parent_comment, created, updated, 0 as depth
+					   like_count, parent_comment, created, updated,
+					   COALESCE(edited_at, '') as edited_at, 0 as depth
 				FROM comments
 				WHERE post = {:postId} AND (parent_comment IS NULL OR parent_comment = '')
 				UNION ALL
 				SELECT c.id, c.post, c.author, c.author_name, c.content,
-					   c.like_count, c.parent_comment, c.created, c.updated, ct.depth + 1
+					   c.like_count, c.parent_comment, c.created, c.updated,
+					   COALESCE(c.edited_at, '') as edited_at, ct.depth + 1
 				FROM comments c
 				INNER JOIN comment_tree ct ON c.parent_comment = ct.id
 			)
@@ -103,6 +107,7 @@ func buildCommentTree(rows []CommentRow) []CommentNode {
 			ParentCommentID: r.ParentCommentID,
 			Created:         r.Created,
 			Updated:         r.Updated,
+			EditedAt:        r.EditedAt,
 			IsLiked:         r.IsLiked == 1,
 			Replies:         []CommentNode{},
 		}
@@ -29,6 +29,11 @@ func HandleIncrementView(app core.App) func(e *core.RequestEvent) error {
 			return apis.NewBadRequestError("type must be 'question' or 'post'", nil)
 		}
 
+		// 작성자가 임시저장/예약 글을 미리 볼 때는 조회수를 올리지 않음
+		if recordUnpublished(app, table, body.ID) {
+			return e.JSON(http.StatusOK, map[string]bool{"success": false})
+		}
+
 		_, err := app.DB().NewQuery(
 			"UPDATE "+table+" SET view_count = view_count + 1 WHERE id = {:id}",
 		).Bind(dbx.Params{"id": body.ID}).Execute()
@@ -132,6 +137,11 @@ func HandleToggleBookmark(app core.App) func(e *core.RequestEvent) error {
 			return apis.NewBadRequestError("post_id is required", nil)
 		}
 
+		// 북마크 해제는 게시 상태와 관계없이 허용
+		if body.Bookmarked && recordUnpublished(app, "community_posts", body.PostID) {
+			return apis.NewBadRequestError("The post is not published", nil)
+		}
+
 		var query string
 		if body.Bookmarked {
 			query = "UPDATE community_posts SET bookmark_count = bookmark_count + 1 WHERE id = {:id}"
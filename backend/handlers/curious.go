@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/pocketbase/pocketbase/apis"
 	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
 	"github.com/pocketbase/dbx"
 )
 
@@ -33,6 +35,17 @@ func HandleToggleCurious(app core.App) func(e *core.RequestEvent) error {
 				dbx.Params{"user": userId, "qid": body.QuestionID},
 			)
 
+			var ownerId string
+			if question, err := txApp.FindRecordById("questions", body.QuestionID); err == nil {
+				if existing == nil && isUnpublished(question) {
+					return apis.NewBadRequestError("The question is not published", nil)
+				}
+				ownerId = question.GetString("owner")
+			}
+			if ownerId == userId {
+				ownerId = ""
+			}
+
 			if existing != nil {
 				if err := txApp.Delete(existing); err != nil {
 					return err
@@ -43,6 +56,9 @@ func HandleToggleCurious(app core.App) func(e *core.RequestEvent) error {
 				if err != nil {
 					return err
 				}
+				if err := awardReputation(txApp, ownerId, -pointsCuriousReceived, "curious_received", body.QuestionID, "question", userId); err != nil {
+					return err
+				}
 				curious = false
 			} else {
 				curiousCollection, err := txApp.FindCollectionByNameOrId("curious")
@@ -61,6 +77,9 @@ func HandleToggleCurious(app core.App) func(e *core.RequestEvent) error {
 				if err != nil {
 					return err
 				}
+				if err := awardReputation(txApp, ownerId, pointsCuriousReceived, "curious_received", body.QuestionID, "question", userId); err != nil {
+					return err
+				}
 				curious = true
 			}
 
@@ -71,6 +90,10 @@ func HandleToggleCurious(app core.App) func(e *core.RequestEvent) error {
 		})
 
 		if err != nil {
+			var apiErr *router.ApiError
+			if errors.As(err, &apiErr) {
+				return apiErr
+			}
 			return apis.NewApiError(http.StatusInternalServerError, "Failed to toggle curious", err)
 		}
 
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"minimo-backend/webpush"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/pocketbase/dbx"
+)
+
+var devicePlatforms = []string{"ios", "android", "web"}
+
+// webSubscriptionToken parses a browser subscription and returns its
+// canonical JSON, so the same subscription always maps to the same token.
+func webSubscriptionToken(raw json.RawMessage) (string, webpush.Subscription, error) {
+	var sub webpush.Subscription
+	if err := json.Unmarshal(raw, &sub); err != nil {
+		return "", sub, apis.NewBadRequestError("Invalid subscription", err)
+	}
+
+	if sub.Endpoint == "" {
+		return "", sub, apis.NewBadRequestError("subscription.endpoint is required", nil)
+	}
+	if sub.Keys.P256dh == "" || sub.Keys.Auth == "" {
+		return "", sub, apis.NewBadRequestError("subscription.keys.p256dh and subscription.keys.auth are required", nil)
+	}
+
+	token, err := json.Marshal(sub)
+	if err != nil {
+		return "", sub, apis.NewBadRequestError("Invalid subscription", err)
+	}
+	return string(token), sub, nil
+}
+
+// checkWebPushEndpoint only accepts https endpoints on public addresses, since
+// the server posts to the endpoint on every push. The push client checks the
+// address again when it connects, as DNS may change after registration.
+func checkWebPushEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Hostname() == "" || u.User != nil {
+		return apis.NewBadRequestError("subscription.endpoint must be an https URL", nil)
+	}
+
+	if webpush.AllowPrivateEndpoints() {
+		if u.Scheme != "https" && u.Scheme != "http" {
+			return apis.NewBadRequestError("subscription.endpoint must be an http(s) URL", nil)
+		}
+		return nil
+	}
+
+	if u.Scheme != "https" {
+		return apis.NewBadRequestError("subscription.endpoint must be an https URL", nil)
+	}
+
+	ips := []net.IP{net.ParseIP(u.Hostname())}
+	if ips[0] == nil {
+		ips, err = net.LookupIP(u.Hostname())
+		if err != nil || len(ips) == 0 {
+			return apis.NewBadRequestError("subscription.endpoint host could not be resolved", err)
+		}
+	}
+	for _, ip := range ips {
+		if !webpush.IsPublicIP(ip) {
+			return apis.NewBadRequestError("subscription.endpoint must be a public host", nil)
+		}
+	}
+	return nil
+}
+
+// HandleRegisterDevice registers a push token for the current user, or
+// refreshes it when the token is already known. Browsers register with
+// platform "web" and their Web Push subscription instead of a token.
+// POST /api/devices
+func HandleRegisterDevice(app core.App) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		var body struct {
+			Token        string          `json:"token"`
+			Subscription json.RawMessage `json:"subscription"`
+			Platform     string          `json:"platform"`
+			AppVersion   string          `json:"app_version"`
+		}
+		if err := json.NewDecoder(e.Request.Body).Decode(&body); err != nil {
+			return apis.NewBadRequestError("Invalid request body", err)
+		}
+
+		// 웹 기기의 토큰은 항상 구독 정보 (token으로 보낸 경우도 같은 검증을 거침)
+		if body.Platform == "web" {
+			raw := body.Subscription
+			if len(raw) == 0 {
+				raw = json.RawMessage(body.Token)
+			}
+			token, sub, err := webSubscriptionToken(raw)
+			if err != nil {
+				return err
+			}
+			if err := checkWebPushEndpoint(sub.Endpoint); err != nil {
+				return err
+			}
+			body.Token = token
+		}
+
+		if body.Token == "" {
+			return apis.NewBadRequestError("token is required", nil)
+		}
+		if !slices.Contains(devicePlatforms, body.Platform) {
+			return apis.NewBadRequestError("platform must be one of ios, android, web", nil)
+		}
+
+		// 같은 토큰이 다른 계정에 등록되어 있으면 현재 계정으로 이전 (기기에서 재로그인한 경우)
+		device, err := app.FindFirstRecordByData("devices", "token", body.Token)
+		if err != nil {
+			collection, err := app.FindCollectionByNameOrId("devices")
+			if err != nil {
+				return apis.NewApiError(http.StatusInternalServerError, "Devices collection not found", err)
+			}
+			device = core.NewRecord(collection)
+			device.Set("token", body.Token)
+		}
+
+		device.Set("user", e.Auth.Id)
+		device.Set("platform", body.Platform)
+		device.Set("app_version", body.AppVersion)
+		device.Set("last_seen", types.NowDateTime())
+
+		if err := app.Save(device); err != nil {
+			return apis.NewApiError(http.StatusInternalServerError, "Failed to register device", err)
+		}
+
+		return e.JSON(http.StatusOK, map[string]any{
+			"id":       device.Id,
+			"platform": device.GetString("platform"),
+		})
+	}
+}
+
+// HandleVapidPublicKey returns the VAPID public key browsers subscribe with.
+// publicKey returns "" when web push is not configured.
+// GET /api/devices/vapid-public-key
+func HandleVapidPublicKey(publicKey func() string) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		key := publicKey()
+		if key == "" {
+			return e.JSON(http.StatusOK, map[string]any{"enabled": false})
+		}
+		return e.JSON(http.StatusOK, map[string]any{
+			"enabled":    true,
+			"public_key": key,
+		})
+	}
+}
+
+// HandleUnregisterDevice removes a push token of the current user, e.g. on logout.
+// POST /api/devices/unregister
+func HandleUnregisterDevice(app core.App) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		var body struct {
+			Token        string          `json:"token"`
+			Subscription json.RawMessage `json:"subscription"`
+		}
+		if err := json.NewDecoder(e.Request.Body).Decode(&body); err != nil {
+			return apis.NewBadRequestError("Invalid request body", err)
+		}
+
+		if len(body.Subscription) > 0 {
+			token, _, err := webSubscriptionToken(body.Subscription)
+			if err != nil {
+				return err
+			}
+			body.Token = token
+		}
+
+		if body.Token == "" {
+			return apis.NewBadRequestError("token is required", nil)
+		}
+
+		result, err := app.DB().NewQuery(
+			"DELETE FROM devices WHERE token = {:token} AND user = {:userId}",
+		).Bind(dbx.Params{"token": body.Token, "userId": e.Auth.Id}).Execute()
+		if err != nil {
+			return apis.NewApiError(http.StatusInternalServerError, "Failed to unregister device", err)
+		}
+
+		// 구버전 앱이 저장한 단일 토큰도 함께 정리
+		_, _ = app.DB().NewQuery(
+			"UPDATE users SET fcm_token = '' WHERE id = {:userId} AND fcm_token = {:token}",
+		).Bind(dbx.Params{"token": body.Token, "userId": e.Auth.Id}).Execute()
+
+		affected, _ := result.RowsAffected()
+
+		return e.JSON(http.StatusOK, map[string]any{
+			"success": affected > 0,
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/dbx"
+)
+
+// publishedCondition returns a SQL condition excluding drafts and
+// scheduled items of the given table alias.
+func publishedCondition(alias string) string {
+	return "COALESCE(" + alias + ".status, '') NOT IN ('draft', 'scheduled')"
+}
+
+// isUnpublished reports whether a post or question is still a draft or scheduled.
+func isUnpublished(record *core.Record) bool {
+	status := record.GetString("status")
+	return status == "draft" || status == "scheduled"
+}
+
+// targetUnpublished reports whether a like target, or the post or question
+// it belongs to, is not published yet.
+func targetUnpublished(app core.App, target *core.Record) bool {
+	switch target.Collection().Name {
+	case "comments":
+		post, err := app.FindRecordById("community_posts", target.GetString("post"))
+		return err == nil && isUnpublished(post)
+	case "answers":
+		question, err := app.FindRecordById("questions", target.GetString("question"))
+		return err == nil && isUnpublished(question)
+	}
+	return isUnpublished(target)
+}
+
+// recordUnpublished reports whether the post or question with the id is
+// still a draft or scheduled.
+func recordUnpublished(app core.App, collectionName, id string) bool {
+	record, err := app.FindRecordById(collectionName, id)
+	return err == nil && isUnpublished(record)
+}
+
+type DraftItem struct {
+	ID        string `db:"id" json:"id"`
+	Type      string `db:"type" json:"type"`
+	Title     string `db:"title" json:"title"`
+	Content   string `db:"content" json:"content"`
+	Status    string `db:"status" json:"status"`
+	PublishAt string `db:"publish_at" json:"publish_at"`
+	Created   string `db:"created" json:"created"`
+	Updated   string `db:"updated" json:"updated"`
+}
+
+// HandleGetDrafts lists the caller's own drafts and scheduled items.
+func HandleGetDrafts(app core.App) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		userId := e.Auth.Id
+		q := e.Request.URL.Query()
+
+		page, _ := strconv.Atoi(q.Get("page"))
+		if page < 1 {
+			page = 1
+		}
+		perPage, _ := strconv.Atoi(q.Get("perPage"))
+		if perPage < 1 || perPage > 100 {
+			perPage = 20
+		}
+		offset := (page - 1) * perPage
+
+		var items []DraftItem
+		err := app.DB().NewQuery(`
+			SELECT * FROM (
+				SELECT
+					id, 'post' as type, '' as title, content, status,
+					COALESCE(publish_at, '') as publish_at, created, updated
+				FROM community_posts
+				WHERE owner = {:userId} AND status IN ('draft', 'scheduled')
+
+				UNION ALL
+
+				SELECT
+					id, 'question' as type, title, content, status,
+					COALESCE(publish_at, '') as publish_at, created, updated
+				FROM questions
+				WHERE owner = {:userId} AND status IN ('draft', 'scheduled')
+			) drafts
+			ORDER BY updated DESC
+			LIMIT {:limit} OFFSET {:offset}
+		`).Bind(dbx.Params{
+			"userId": userId,
+			"limit":  perPage,
+			"offset": offset,
+		}).All(&items)
+
+		if err != nil {
+			return apis.NewApiError(http.StatusInternalServerError, "Failed to fetch drafts", err)
+		}
+
+		if items == nil {
+			items = []DraftItem{}
+		}
+
+		return e.JSON(http.StatusOK, map[string]any{
+			"items":   items,
+			"page":    page,
+			"perPage": perPage,
+		})
+	}
+}
@@ -59,6 +59,7 @@ func HandleTrendingFeed(app core.App) func(e *core.RequestEvent) error {
 					created
 				FROM community_posts
 				WHERE created >= datetime('now', '-' || {:hours} || ' hours')
+					AND `+publishedCondition("community_posts")+`
 
 				UNION ALL
 
@@ -71,6 +72,7 @@ func HandleTrendingFeed(app core.App) func(e *core.RequestEvent) error {
 					created
 				FROM questions
 				WHERE created >= datetime('now', '-' || {:hours} || ' hours')
+					AND `+publishedCondition("questions")+`
 			) combined
 			ORDER BY score DESC
 			LIMIT {:limit} OFFSET {:offset}
@@ -62,38 +62,9 @@ func HandleToggleFollow(app core.App) func(e *core.RequestEvent) error {
 			return apis.NewApiError(http.StatusInternalServerError, "Failed to toggle follow", err)
 		}
 
-		if following {
-			go createFollowNotification(app, followerId, body.FollowingID)
-		}
-
+		// 팔로우 알림은 follows 생성 훅에서 한 번만 발송
 		return e.JSON(http.StatusOK, map[string]any{
 			"following": following,
 		})
 	}
 }
-
-func createFollowNotification(app core.App, followerId, followingId string) {
-	follower, err := app.FindRecordById("users", followerId)
-	if err != nil {
-		return
-	}
-	followerName := follower.GetString("name")
-	if followerName == "" {
-		followerName = "회원"
-	}
-
-	notifCollection, err := app.FindCollectionByNameOrId("notifications")
-	if err != nil {
-		return
-	}
-	notif := core.NewRecord(notifCollection)
-	notif.Set("user", followingId)
-	notif.Set("type", "follow")
-	notif.Set("title", "새 팔로워")
-	notif.Set("message", followerName+"님이 회원님을 팔로우합니다.")
-	notif.Set("target_id", followerId)
-	notif.Set("target_type", "user")
-	notif.Set("is_read", false)
-	notif.Set("actor", followerId)
-	_ = app.Save(notif)
-}
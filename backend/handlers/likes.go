@@ -2,10 +2,14 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
+	"minimo-backend/notify"
+
 	"github.com/pocketbase/pocketbase/apis"
 	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
 	"github.com/pocketbase/dbx"
 )
 
@@ -45,6 +49,18 @@ func HandleToggleLike(app core.App) func(e *core.RequestEvent) error {
 				dbx.Params{"user": userId, "tid": body.TargetID, "tt": body.TargetType},
 			)
 
+			// 본인 콘텐츠에 대한 좋아요는 평판 점수에 반영하지 않음
+			var authorId string
+			if target, err := txApp.FindRecordById(collectionName, body.TargetID); err == nil {
+				if existing == nil && targetUnpublished(txApp, target) {
+					return apis.NewBadRequestError("The content is not published", nil)
+				}
+				authorId = contentAuthorId(target)
+			}
+			if authorId == userId {
+				authorId = ""
+			}
+
 			if existing != nil {
 				if err := txApp.Delete(existing); err != nil {
 					return err
@@ -55,6 +71,9 @@ func HandleToggleLike(app core.App) func(e *core.RequestEvent) error {
 				if err != nil {
 					return err
 				}
+				if err := awardReputation(txApp, authorId, -pointsLikeReceived, "like_received", body.TargetID, body.TargetType, userId); err != nil {
+					return err
+				}
 				liked = false
 			} else {
 				likesCollection, err := txApp.FindCollectionByNameOrId("likes")
@@ -74,6 +93,9 @@ func HandleToggleLike(app core.App) func(e *core.RequestEvent) error {
 				if err != nil {
 					return err
 				}
+				if err := awardReputation(txApp, authorId, pointsLikeReceived, "like_received", body.TargetID, body.TargetType, userId); err != nil {
+					return err
+				}
 				liked = true
 			}
 
@@ -84,6 +106,10 @@ func HandleToggleLike(app core.App) func(e *core.RequestEvent) error {
 		})
 
 		if err != nil {
+			var apiErr *router.ApiError
+			if errors.As(err, &apiErr) {
+				return apiErr
+			}
 			return apis.NewApiError(http.StatusInternalServerError, "Failed to toggle like", err)
 		}
 
@@ -104,63 +130,18 @@ func createLikeNotification(app core.App, userId, targetId, targetType, collecti
 		return
 	}
 
-	authorId := target.GetString("author")
-	if authorId == "" {
-		authorId = target.GetString("owner")
-	}
-	if authorId == "" || authorId == userId {
-		return
-	}
-
-	liker, err := app.FindRecordById("users", userId)
-	if err != nil {
-		return
-	}
-	likerName := liker.GetString("name")
-	if likerName == "" {
-		likerName = "회원"
-	}
-
-	var message, notifTargetType, notifTargetId string
+	// 댓글/답변 좋아요는 상위 게시글/질문으로 이동하도록 대상 지정
+	notifTargetType, notifTargetId := targetType, targetId
 	switch targetType {
-	case "post":
-		message = likerName + "님이 회원님의 게시글을 좋아합니다."
-		notifTargetType = "post"
-		notifTargetId = targetId
 	case "comment":
-		message = likerName + "님이 회원님의 댓글을 좋아합니다."
-		postId := target.GetString("post")
-		if postId != "" {
-			notifTargetType = "post"
-			notifTargetId = postId
-		} else {
-			notifTargetType = "comment"
-			notifTargetId = targetId
+		if postId := target.GetString("post"); postId != "" {
+			notifTargetType, notifTargetId = "post", postId
 		}
 	case "answer":
-		message = likerName + "님이 회원님의 답변을 좋아합니다."
-		questionId := target.GetString("question")
-		if questionId != "" {
-			notifTargetType = "question"
-			notifTargetId = questionId
-		} else {
-			notifTargetType = "answer"
-			notifTargetId = targetId
+		if questionId := target.GetString("question"); questionId != "" {
+			notifTargetType, notifTargetId = "question", questionId
 		}
 	}
 
-	notifCollection, err := app.FindCollectionByNameOrId("notifications")
-	if err != nil {
-		return
-	}
-	notif := core.NewRecord(notifCollection)
-	notif.Set("user", authorId)
-	notif.Set("type", "like")
-	notif.Set("title", "좋아요")
-	notif.Set("message", message)
-	notif.Set("target_id", notifTargetId)
-	notif.Set("target_type", notifTargetType)
-	notif.Set("is_read", false)
-	notif.Set("actor", userId)
-	_ = app.Save(notif)
+	notify.Send(app, notify.Like(contentAuthorId(target), userId, targetType, targetId, notifTargetId, notifTargetType))
 }
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"time"
+
+	"minimo-backend/notify"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/dbx"
+)
+
+var notificationTypes = []string{"like", "comment", "follow", "answer", "mention", "system"}
+
+type QuietHours struct {
+	Enabled  bool   `json:"enabled"`
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Timezone string `json:"timezone"`
+}
+
+type NotificationPreferences struct {
+	InApp      map[string]bool `json:"in_app"`
+	Push       map[string]bool `json:"push"`
+	QuietHours *QuietHours     `json:"quiet_hours"`
+	// 주간 소식 이메일 수신 여부 (opt-in)
+	EmailDigest *bool `json:"email_digest"`
+	// 알림, 푸시, 이메일을 받을 언어 (users.locale)
+	Locale *string `json:"locale"`
+}
+
+// HandleGetNotificationPreferences returns the in-app and push toggles per
+// notification type, quiet hours, the weekly email digest opt-in and the
+// language notifications are sent in.
+// GET /api/notifications/preferences
+func HandleGetNotificationPreferences(app core.App) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		record, _ := findNotificationPreferences(app, e.Auth.Id)
+		return e.JSON(http.StatusOK, toNotificationPreferences(record, e.Auth))
+	}
+}
+
+// HandleUpdateNotificationPreferences updates the toggles given in the body.
+// Types that are omitted keep their current setting, as do quiet hours when
+// quiet_hours is absent.
+// PATCH /api/notifications/preferences
+func HandleUpdateNotificationPreferences(app core.App) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		var body NotificationPreferences
+		if err := json.NewDecoder(e.Request.Body).Decode(&body); err != nil {
+			return apis.NewBadRequestError("Invalid request body", err)
+		}
+
+		for _, toggles := range []map[string]bool{body.InApp, body.Push} {
+			for notifType := range toggles {
+				if !slices.Contains(notificationTypes, notifType) {
+					return apis.NewBadRequestError("Invalid notification type: "+notifType, nil)
+				}
+			}
+		}
+
+		if qh := body.QuietHours; qh != nil {
+			if _, err := time.Parse("15:04", qh.Start); err != nil {
+				return apis.NewBadRequestError("quiet_hours.start must be HH:MM", nil)
+			}
+			if _, err := time.Parse("15:04", qh.End); err != nil {
+				return apis.NewBadRequestError("quiet_hours.end must be HH:MM", nil)
+			}
+			if qh.Timezone == "" {
+				qh.Timezone = notify.DefaultTimezone
+			}
+			if _, err := time.LoadLocation(qh.Timezone); err != nil {
+				return apis.NewBadRequestError("Invalid quiet_hours.timezone", err)
+			}
+		}
+
+		if body.Locale != nil && !slices.Contains(notify.Locales, *body.Locale) {
+			return apis.NewBadRequestError("locale must be one of ko, en", nil)
+		}
+
+		record, err := findNotificationPreferences(app, e.Auth.Id)
+		if err != nil {
+			collection, err := app.FindCollectionByNameOrId("notification_preferences")
+			if err != nil {
+				return apis.NewApiError(http.StatusInternalServerError, "Preferences collection not found", err)
+			}
+			record = core.NewRecord(collection)
+			record.Set("user", e.Auth.Id)
+		}
+
+		record.Set("in_app_muted", applyToggles(record.GetStringSlice("in_app_muted"), body.InApp))
+		record.Set("push_muted", applyToggles(record.GetStringSlice("push_muted"), body.Push))
+		if qh := body.QuietHours; qh != nil {
+			record.Set("quiet_hours_enabled", qh.Enabled)
+			record.Set("quiet_start", qh.Start)
+			record.Set("quiet_end", qh.End)
+			record.Set("timezone", qh.Timezone)
+		}
+		if body.EmailDigest != nil {
+			record.Set("email_digest", *body.EmailDigest)
+			if *body.EmailDigest && record.GetString("digest_token") == "" {
+				record.Set("digest_token", security.RandomString(32))
+			}
+		}
+
+		if err := app.Save(record); err != nil {
+			return apis.NewApiError(http.StatusInternalServerError, "Failed to save preferences", err)
+		}
+
+		if body.Locale != nil && *body.Locale != e.Auth.GetString("locale") {
+			e.Auth.Set("locale", *body.Locale)
+			if err := app.Save(e.Auth); err != nil {
+				return apis.NewApiError(http.StatusInternalServerError, "Failed to save locale", err)
+			}
+		}
+
+		return e.JSON(http.StatusOK, toNotificationPreferences(record, e.Auth))
+	}
+}
+
+func findNotificationPreferences(app core.App, userId string) (*core.Record, error) {
+	return app.FindFirstRecordByFilter("notification_preferences",
+		"user = {:user}", dbx.Params{"user": userId})
+}
+
+func toNotificationPreferences(record, user *core.Record) NotificationPreferences {
+	var inAppMuted, pushMuted []string
+	if record != nil {
+		inAppMuted = record.GetStringSlice("in_app_muted")
+		pushMuted = record.GetStringSlice("push_muted")
+	}
+
+	prefs := NotificationPreferences{
+		InApp: map[string]bool{},
+		Push:  map[string]bool{},
+		QuietHours: &QuietHours{
+			Start:    "22:00",
+			End:      "07:00",
+			Timezone: notify.DefaultTimezone,
+		},
+	}
+	if record != nil && record.GetString("quiet_start") != "" {
+		prefs.QuietHours = &QuietHours{
+			Enabled:  record.GetBool("quiet_hours_enabled"),
+			Start:    record.GetString("quiet_start"),
+			End:      record.GetString("quiet_end"),
+			Timezone: record.GetString("timezone"),
+		}
+	}
+	emailDigest := record != nil && record.GetBool("email_digest")
+	prefs.EmailDigest = &emailDigest
+	locale := notify.LocaleOf(user)
+	prefs.Locale = &locale
+
+	for _, notifType := range notificationTypes {
+		prefs.InApp[notifType] = !slices.Contains(inAppMuted, notifType)
+		prefs.Push[notifType] = !slices.Contains(pushMuted, notifType)
+	}
+	return prefs
+}
+
+// applyToggles returns the muted type list after applying enabled/disabled toggles.
+func applyToggles(muted []string, toggles map[string]bool) []string {
+	result := []string{}
+	for _, notifType := range notificationTypes {
+		enabled, ok := toggles[notifType]
+		if !ok {
+			enabled = !slices.Contains(muted, notifType)
+		}
+		if !enabled {
+			result = append(result, notifType)
+		}
+	}
+	return result
+}
@@ -1,13 +1,259 @@
 package handlers
 
 import (
+	"encoding/json"
 	"net/http"
+	"strconv"
+
+	"minimo-backend/notify"
 
 	"github.com/pocketbase/pocketbase/apis"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/dbx"
 )
 
+type NotificationActor struct {
+	ID     string `db:"id" json:"id"`
+	Name   string `db:"name" json:"name"`
+	Avatar string `db:"avatar" json:"avatar"`
+}
+
+type NotificationItem struct {
+	ID              string              `db:"id" json:"id"`
+	Type            string              `db:"type" json:"type"`
+	Title           string              `db:"title" json:"title"`
+	Message         string              `db:"message" json:"message"`
+	TargetID        string              `db:"target_id" json:"target_id"`
+	TargetType      string              `db:"target_type" json:"target_type"`
+	TargetTitle     string              `db:"target_title" json:"target_title"`
+	TargetSnippet   string              `db:"target_snippet" json:"target_snippet"`
+	IsReadInt       int                 `db:"is_read" json:"-"`
+	IsRead          bool                `json:"is_read"`
+	Actor           *NotificationActor  `json:"actor"`
+	ActorID         string              `db:"actor" json:"-"`
+	ActorName       string              `db:"actor_name" json:"-"`
+	ActorAvatar     string              `db:"actor_avatar" json:"-"`
+	ActorCount      int                 `db:"actor_count" json:"actor_count"`
+	RecentActorsRaw string              `db:"recent_actors" json:"-"`
+	RecentActors    []NotificationActor `json:"recent_actors"`
+	Template        string              `db:"template" json:"-"`
+	EventDataRaw    string              `db:"event_data" json:"-"`
+	Created         string              `db:"created" json:"created"`
+	Updated         string              `db:"updated" json:"updated"`
+}
+
+// HandleGetNotifications returns the current user's notifications with the
+// actor's profile and the title/snippet of the target content. Titles and
+// messages are rendered in the user's current locale.
+// GET /api/notifications?page=1&perPage=20&unread=true
+func HandleGetNotifications(app core.App) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		userId := e.Auth.Id
+		q := e.Request.URL.Query()
+
+		page, _ := strconv.Atoi(q.Get("page"))
+		if page < 1 {
+			page = 1
+		}
+		perPage, _ := strconv.Atoi(q.Get("perPage"))
+		if perPage < 1 || perPage > 100 {
+			perPage = 20
+		}
+		offset := (page - 1) * perPage
+
+		filterSQL := "WHERE n.user = {:userId}"
+		if q.Get("unread") == "true" {
+			filterSQL += " AND n.is_read = 0"
+		}
+
+		var items []NotificationItem
+		err := app.DB().NewQuery(`
+			SELECT
+				n.id, n.type, n.title,
+				COALESCE(n.message, '') as message,
+				COALESCE(n.target_id, '') as target_id,
+				COALESCE(n.target_type, '') as target_type,
+				CASE n.target_type
+					WHEN 'question' THEN COALESCE((SELECT q.title FROM questions q WHERE q.id = n.target_id AND COALESCE(q.status, '') IN ('', 'active')), '')
+					WHEN 'user' THEN COALESCE((SELECT tu.name FROM users tu WHERE tu.id = n.target_id), '')
+					ELSE ''
+				END as target_title,
+				SUBSTR(COALESCE(CASE n.target_type
+					WHEN 'post' THEN (SELECT p.content FROM community_posts p WHERE p.id = n.target_id AND COALESCE(p.status, '') IN ('', 'active'))
+					WHEN 'question' THEN (SELECT q.content FROM questions q WHERE q.id = n.target_id AND COALESCE(q.status, '') IN ('', 'active'))
+					WHEN 'comment' THEN (SELECT c.content FROM comments c JOIN community_posts p ON p.id = c.post WHERE c.id = n.target_id AND COALESCE(p.status, '') IN ('', 'active'))
+					WHEN 'answer' THEN (SELECT a.content FROM answers a JOIN questions q ON q.id = a.question WHERE a.id = n.target_id AND COALESCE(q.status, '') IN ('', 'active'))
+				END, ''), 1, 100) as target_snippet,
+				n.is_read,
+				COALESCE(n.actor, '') as actor,
+				COALESCE(u.name, '') as actor_name,
+				COALESCE(u.avatar, '') as actor_avatar,
+				MAX(COALESCE(n.actor_count, 0), 1) as actor_count,
+				COALESCE(n.recent_actors, '') as recent_actors,
+				COALESCE(n.template, '') as template,
+				COALESCE(n.event_data, '') as event_data,
+				n.created, n.updated
+			FROM notifications n
+			LEFT JOIN users u ON u.id = n.actor
+			` + filterSQL + `
+			ORDER BY n.created DESC
+			LIMIT {:limit} OFFSET {:offset}
+		`).Bind(dbx.Params{
+			"userId": userId,
+			"limit":  perPage,
+			"offset": offset,
+		}).All(&items)
+
+		if err != nil {
+			return apis.NewApiError(http.StatusInternalServerError, "Failed to fetch notifications", err)
+		}
+
+		var total int
+		_ = app.DB().NewQuery(
+			"SELECT COUNT(*) FROM notifications n " + filterSQL,
+		).Bind(dbx.Params{"userId": userId}).Row(&total)
+
+		// 묶음 알림의 최근 행위자 프로필을 한 번에 조회
+		var actorIds []any
+		for i := range items {
+			for _, id := range parseStringList(items[i].RecentActorsRaw) {
+				actorIds = append(actorIds, id)
+			}
+		}
+		actors := map[string]NotificationActor{}
+		if len(actorIds) > 0 {
+			var rows []NotificationActor
+			_ = app.DB().Select("id", "COALESCE(name, '') as name", "COALESCE(avatar, '') as avatar").
+				From("users").
+				Where(dbx.In("id", actorIds...)).
+				All(&rows)
+			for _, row := range rows {
+				actors[row.ID] = row
+			}
+		}
+
+		locale := notify.LocaleOf(e.Auth)
+
+		for i := range items {
+			item := &items[i]
+			item.IsRead = item.IsReadInt == 1
+			if item.Template != "" {
+				data := map[string]string{}
+				_ = json.Unmarshal([]byte(item.EventDataRaw), &data)
+				if data["actor"] == "" {
+					data["actor"] = item.ActorName
+				}
+				if title, message, ok := notify.Render(locale, item.Template, data, item.ActorCount); ok {
+					item.Title, item.Message = title, message
+				}
+			}
+			if item.ActorID != "" {
+				item.Actor = &NotificationActor{ID: item.ActorID, Name: item.ActorName, Avatar: item.ActorAvatar}
+			}
+			item.RecentActors = []NotificationActor{}
+			for _, id := range parseStringList(item.RecentActorsRaw) {
+				if actor, ok := actors[id]; ok {
+					item.RecentActors = append(item.RecentActors, actor)
+				}
+			}
+		}
+
+		if items == nil {
+			items = []NotificationItem{}
+		}
+
+		return e.JSON(http.StatusOK, map[string]any{
+			"items":      items,
+			"page":       page,
+			"perPage":    perPage,
+			"totalItems": total,
+			"totalPages": (total + perPage - 1) / perPage,
+		})
+	}
+}
+
+// HandleMarkRead marks a single notification of the current user as read.
+// POST /api/notifications/{id}/read
+func HandleMarkRead(app core.App) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		record, err := findOwnNotification(app, e)
+		if err != nil {
+			return err
+		}
+
+		if !record.GetBool("is_read") {
+			record.Set("is_read", true)
+			if err := app.Save(record); err != nil {
+				return apis.NewApiError(http.StatusInternalServerError, "Failed to mark notification", err)
+			}
+		}
+
+		return e.JSON(http.StatusOK, map[string]any{
+			"success": true,
+		})
+	}
+}
+
+// HandleDeleteNotification deletes a single notification of the current user.
+// DELETE /api/notifications/{id}
+func HandleDeleteNotification(app core.App) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		record, err := findOwnNotification(app, e)
+		if err != nil {
+			return err
+		}
+
+		if err := app.Delete(record); err != nil {
+			return apis.NewApiError(http.StatusInternalServerError, "Failed to delete notification", err)
+		}
+
+		return e.NoContent(http.StatusNoContent)
+	}
+}
+
+// HandleDeleteReadNotifications deletes every read notification of the current
+// user. Records are deleted one by one so the realtime delete events reach the
+// user's other devices.
+// DELETE /api/notifications/read
+func HandleDeleteReadNotifications(app core.App) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		records, err := app.FindRecordsByFilter("notifications",
+			"user = {:userId} && is_read = true", "", 0, 0,
+			dbx.Params{"userId": e.Auth.Id},
+		)
+		if err != nil {
+			return apis.NewApiError(http.StatusInternalServerError, "Failed to delete notifications", err)
+		}
+
+		err = app.RunInTransaction(func(txApp core.App) error {
+			for _, record := range records {
+				if err := txApp.Delete(record); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return apis.NewApiError(http.StatusInternalServerError, "Failed to delete notifications", err)
+		}
+
+		return e.JSON(http.StatusOK, map[string]any{
+			"success": true,
+			"count":   len(records),
+		})
+	}
+}
+
+// findOwnNotification loads the notification from the path and checks that it
+// belongs to the current user. Other users' notifications are reported as not found.
+func findOwnNotification(app core.App, e *core.RequestEvent) (*core.Record, error) {
+	record, err := app.FindRecordById("notifications", e.Request.PathValue("id"))
+	if err != nil || record.GetString("user") != e.Auth.Id {
+		return nil, apis.NewNotFoundError("Notification not found", err)
+	}
+	return record, nil
+}
+
 func HandleMarkAllRead(app core.App) func(e *core.RequestEvent) error {
 	return func(e *core.RequestEvent) error {
 		userId := e.Auth.Id
@@ -21,6 +267,10 @@ func HandleMarkAllRead(app core.App) func(e *core.RequestEvent) error {
 		}
 
 		affected, _ := result.RowsAffected()
+		if affected > 0 {
+			// 모든 기기의 앱 배지를 0으로 동기화
+			notify.UnreadChanged(app, userId)
+		}
 
 		return e.JSON(http.StatusOK, map[string]any{
 			"success": true,
@@ -33,11 +283,7 @@ func HandleUnreadCount(app core.App) func(e *core.RequestEvent) error {
 	return func(e *core.RequestEvent) error {
 		userId := e.Auth.Id
 
-		var count int
-		err := app.DB().NewQuery(
-			"SELECT COUNT(*) FROM notifications WHERE user = {:userId} AND is_read = 0",
-		).Bind(dbx.Params{"userId": userId}).Row(&count)
-
+		count, err := notify.CountUnread(app, userId)
 		if err != nil {
 			return apis.NewApiError(http.StatusInternalServerError, "Failed to count notifications", err)
 		}
@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/pocketbase/dbx"
+)
+
+const (
+	maxPollOptions      = 10
+	maxPollOptionLength = 100
+)
+
+type PollOption struct {
+	ID        string `db:"id" json:"id"`
+	Poll      string `db:"poll" json:"-"`
+	Label     string `db:"label" json:"label"`
+	VoteCount int    `db:"vote_count" json:"vote_count"`
+}
+
+type PollResponse struct {
+	ID          string       `db:"id" json:"id"`
+	Post        string       `db:"post" json:"-"`
+	MultipleInt int          `db:"multiple" json:"-"`
+	Multiple    bool         `json:"multiple"`
+	ClosesAt    string       `db:"closes_at" json:"closes_at"`
+	ClosedInt   int          `db:"closed" json:"-"`
+	Closed      bool         `json:"closed"`
+	TotalVotes  int          `json:"total_votes"`
+	Options     []PollOption `json:"options"`
+	MyVotes     []string     `json:"my_votes"`
+}
+
+// loadPolls returns the polls attached to the given posts keyed by post id,
+// including tallies and the options the user voted for.
+func loadPolls(app core.App, userId string, postIds []string) map[string]*PollResponse {
+	result := map[string]*PollResponse{}
+	if len(postIds) == 0 {
+		return result
+	}
+
+	ids := make([]any, len(postIds))
+	for i, id := range postIds {
+		ids[i] = id
+	}
+
+	var polls []PollResponse
+	err := app.DB().Select("id", "post", "multiple", "closes_at", "closed").
+		From("polls").
+		Where(dbx.In("post", ids...)).
+		All(&polls)
+	if err != nil || len(polls) == 0 {
+		return result
+	}
+
+	pollIds := make([]any, len(polls))
+	byPoll := map[string]*PollResponse{}
+	for i := range polls {
+		polls[i].Multiple = polls[i].MultipleInt == 1
+		polls[i].Closed = polls[i].ClosedInt == 1
+		polls[i].Options = []PollOption{}
+		polls[i].MyVotes = []string{}
+		pollIds[i] = polls[i].ID
+		byPoll[polls[i].ID] = &polls[i]
+		result[polls[i].Post] = &polls[i]
+	}
+
+	var options []PollOption
+	_ = app.DB().Select("id", "poll", "label", "vote_count").
+		From("poll_options").
+		Where(dbx.In("poll", pollIds...)).
+		OrderBy("position ASC").
+		All(&options)
+	for _, opt := range options {
+		if poll, ok := byPoll[opt.Poll]; ok {
+			poll.Options = append(poll.Options, opt)
+			poll.TotalVotes += opt.VoteCount
+		}
+	}
+
+	var votes []struct {
+		Poll   string `db:"poll"`
+		Option string `db:"option"`
+	}
+	_ = app.DB().Select("poll", "option").
+		From("poll_votes").
+		Where(dbx.And(dbx.In("poll", pollIds...), dbx.HashExp{"user": userId})).
+		All(&votes)
+	for _, v := range votes {
+		if poll, ok := byPoll[v.Poll]; ok {
+			poll.MyVotes = append(poll.MyVotes, v.Option)
+		}
+	}
+
+	return result
+}
+
+func HandleCreatePoll(app core.App) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		userId := e.Auth.Id
+		postId := e.Request.PathValue("id")
+
+		var body struct {
+			Options  []string `json:"options"`
+			Multiple bool     `json:"multiple"`
+			ClosesAt string   `json:"closes_at"`
+		}
+		if err := json.NewDecoder(e.Request.Body).Decode(&body); err != nil {
+			return apis.NewBadRequestError("Invalid request body", err)
+		}
+
+		var labels []string
+		for _, opt := range body.Options {
+			opt = strings.TrimSpace(opt)
+			if opt == "" {
+				continue
+			}
+			if len([]rune(opt)) > maxPollOptionLength {
+				return apis.NewBadRequestError("Poll option is too long", nil)
+			}
+			labels = append(labels, opt)
+		}
+		if len(labels) < 2 || len(labels) > maxPollOptions {
+			return apis.NewBadRequestError("A poll needs between 2 and 10 options", nil)
+		}
+
+		var closesAt types.DateTime
+		if body.ClosesAt != "" {
+			parsed, err := types.ParseDateTime(body.ClosesAt)
+			if err != nil || parsed.IsZero() {
+				return apis.NewBadRequestError("Invalid closes_at", err)
+			}
+			if !parsed.Time().After(time.Now()) {
+				return apis.NewBadRequestError("closes_at must be in the future", nil)
+			}
+			closesAt = parsed
+		}
+
+		post, err := app.FindRecordById("community_posts", postId)
+		if err != nil {
+			return apis.NewNotFoundError("Post not found", err)
+		}
+		if post.GetString("owner") != userId {
+			return apis.NewForbiddenError("Only the post author can add a poll", nil)
+		}
+
+		existing, _ := app.FindFirstRecordByFilter("polls", "post = {:post}", dbx.Params{"post": postId})
+		if existing != nil {
+			return apis.NewBadRequestError("The post already has a poll", nil)
+		}
+
+		err = app.RunInTransaction(func(txApp core.App) error {
+			pollsCollection, err := txApp.FindCollectionByNameOrId("polls")
+			if err != nil {
+				return err
+			}
+			poll := core.NewRecord(pollsCollection)
+			poll.Set("post", postId)
+			poll.Set("multiple", body.Multiple)
+			poll.Set("closes_at", closesAt)
+			poll.Set("closed", false)
+			if err := txApp.Save(poll); err != nil {
+				return err
+			}
+
+			optionsCollection, err := txApp.FindCollectionByNameOrId("poll_options")
+			if err != nil {
+				return err
+			}
+			for i, label := range labels {
+				option := core.NewRecord(optionsCollection)
+				option.Set("poll", poll.Id)
+				option.Set("label", label)
+				option.Set("position", i)
+				option.Set("vote_count", 0)
+				if err := txApp.Save(option); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			return apis.NewApiError(http.StatusInternalServerError, "Failed to create poll", err)
+		}
+
+		return e.JSON(http.StatusOK, loadPolls(app, userId, []string{postId})[postId])
+	}
+}
+
+func HandleVotePoll(app core.App) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		userId := e.Auth.Id
+		pollId := e.Request.PathValue("id")
+
+		var body struct {
+			OptionIDs []string `json:"option_ids"`
+		}
+		if err := json.NewDecoder(e.Request.Body).Decode(&body); err != nil {
+			return apis.NewBadRequestError("Invalid request body", err)
+		}
+		if len(body.OptionIDs) == 0 {
+			return apis.NewBadRequestError("option_ids is required", nil)
+		}
+
+		var postId string
+
+		err := app.RunInTransaction(func(txApp core.App) error {
+			poll, err := txApp.FindRecordById("polls", pollId)
+			if err != nil {
+				return apis.NewNotFoundError("Poll not found", err)
+			}
+			postId = poll.GetString("post")
+
+			post, err := txApp.FindRecordById("community_posts", postId)
+			if err != nil {
+				return apis.NewNotFoundError("Post not found", err)
+			}
+			if status := post.GetString("status"); status != "" && status != "active" {
+				return apis.NewBadRequestError("The post is not published", nil)
+			}
+
+			closesAt := poll.GetDateTime("closes_at")
+			if poll.GetBool("closed") || (!closesAt.IsZero() && closesAt.Time().Before(time.Now())) {
+				return apis.NewBadRequestError("The poll is closed", nil)
+			}
+
+			multiple := poll.GetBool("multiple")
+			if !multiple && len(body.OptionIDs) > 1 {
+				return apis.NewBadRequestError("Only one option can be selected", nil)
+			}
+
+			existing, _ := txApp.FindFirstRecordByFilter("poll_votes",
+				"poll = {:poll} && user = {:user}",
+				dbx.Params{"poll": pollId, "user": userId},
+			)
+			if existing != nil {
+				return apis.NewBadRequestError("You have already voted", nil)
+			}
+
+			votesCollection, err := txApp.FindCollectionByNameOrId("poll_votes")
+			if err != nil {
+				return err
+			}
+
+			seen := map[string]bool{}
+			for _, optionId := range body.OptionIDs {
+				if seen[optionId] {
+					continue
+				}
+				seen[optionId] = true
+
+				option, err := txApp.FindRecordById("poll_options", optionId)
+				if err != nil || option.GetString("poll") != pollId {
+					return apis.NewBadRequestError("Invalid option", err)
+				}
+
+				vote := core.NewRecord(votesCollection)
+				vote.Set("poll", pollId)
+				vote.Set("option", optionId)
+				vote.Set("user", userId)
+				if multiple {
+					vote.Set("slot", optionId)
+				} else {
+					vote.Set("slot", "single")
+				}
+				if err := txApp.Save(vote); err != nil {
+					// 동시에 들어온 중복 투표는 유니크 인덱스에서 걸림
+					if isUniqueViolation(err) {
+						return apis.NewBadRequestError("You have already voted", nil)
+					}
+					return err
+				}
+
+				_, err = txApp.DB().NewQuery(
+					"UPDATE poll_options SET vote_count = vote_count + 1 WHERE id = {:id}",
+				).Bind(dbx.Params{"id": optionId}).Execute()
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			var apiErr *router.ApiError
+			if errors.As(err, &apiErr) {
+				return apiErr
+			}
+			return apis.NewApiError(http.StatusInternalServerError, "Failed to vote", err)
+		}
+
+		return e.JSON(http.StatusOK, loadPolls(app, userId, []string{postId})[postId])
+	}
+}
+
+// isUniqueViolation reports whether a save failed on a unique index, which
+// PocketBase reports as a "validation_not_unique" field error.
+func isUniqueViolation(err error) bool {
+	var fieldErrs validation.Errors
+	if !errors.As(err, &fieldErrs) {
+		return false
+	}
+	for _, fieldErr := range fieldErrs {
+		var validationErr validation.Error
+		if errors.As(fieldErr, &validationErr) && validationErr.Code() == "validation_not_unique" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// postImageURLs returns the image URLs of a post in display order.
+// Posts created before multi-image support only have the legacy image field.
+func postImageURLs(app core.App, collectionId, postId string, filenames []string, legacyImage string) []string {
+	if len(filenames) == 0 && legacyImage != "" {
+		filenames = []string{legacyImage}
+	}
+
+	urls := make([]string, 0, len(filenames))
+	for _, filename := range filenames {
+		urls = append(urls, fileURL(app, collectionId, postId, filename))
+	}
+	return urls
+}
+
+// parseStringList decodes a multi-value (file or relation) column as stored in the database.
+func parseStringList(raw string) []string {
+	var filenames []string
+	if raw != "" {
+		_ = json.Unmarshal([]byte(raw), &filenames)
+	}
+	return filenames
+}
+
+// HandleReorderPostImages sets the display order of a post's images.
+// PATCH /api/community/posts/{id}/images/order
+func HandleReorderPostImages(app core.App) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		postId := e.Request.PathValue("id")
+
+		var body struct {
+			Images []string `json:"images"`
+		}
+		if err := json.NewDecoder(e.Request.Body).Decode(&body); err != nil {
+			return apis.NewBadRequestError("Invalid request body", err)
+		}
+
+		post, err := app.FindRecordById("community_posts", postId)
+		if err != nil {
+			return apis.NewNotFoundError("Post not found", err)
+		}
+		if post.GetString("owner") != e.Auth.Id {
+			return apis.NewForbiddenError("Only the post author can reorder images", nil)
+		}
+
+		// 새 순서는 기존 이미지 목록의 순열이어야 함
+		current := post.GetStringSlice("images")
+		sortedCurrent := slices.Clone(current)
+		sortedNew := slices.Clone(body.Images)
+		slices.Sort(sortedCurrent)
+		slices.Sort(sortedNew)
+		if !slices.Equal(sortedCurrent, sortedNew) {
+			return apis.NewBadRequestError("images must contain exactly the current post images", nil)
+		}
+
+		post.Set("images", body.Images)
+		if err := app.Save(post); err != nil {
+			return apis.NewApiError(http.StatusInternalServerError, "Failed to reorder images", err)
+		}
+
+		return e.JSON(http.StatusOK, map[string]any{
+			"images": postImageURLs(app, post.Collection().Id, post.Id, post.GetStringSlice("images"), post.GetString("image")),
+		})
+	}
+}
@@ -10,20 +10,24 @@ import (
 )
 
 type PostResponse struct {
-	ID            string   `db:"id" json:"id"`
-	Owner         string   `db:"owner" json:"owner"`
-	AuthorName    string   `db:"author_name" json:"author_name"`
-	AuthorImage   string   `db:"author_image" json:"author_image"`
-	Content       string   `db:"content" json:"content"`
-	Image         string   `db:"image" json:"image"`
-	LikeCount     int      `db:"like_count" json:"like_count"`
-	CommentCount  int      `db:"comment_count" json:"comment_count"`
-	BookmarkCount int      `db:"bookmark_count" json:"bookmark_count"`
-	Created       string   `db:"created" json:"created"`
-	Updated       string   `db:"updated" json:"updated"`
-	IsLiked       int      `db:"is_liked" json:"-"`
-	IsLikedBool   bool     `json:"is_liked"`
-	Tags          []string `json:"tags"`
+	ID            string        `db:"id" json:"id"`
+	Owner         string        `db:"owner" json:"owner"`
+	AuthorName    string        `db:"author_name" json:"author_name"`
+	AuthorImage   string        `db:"author_image" json:"author_image"`
+	Content       string        `db:"content" json:"content"`
+	Image         string        `db:"image" json:"image"`
+	ImagesRaw     string        `db:"images" json:"-"`
+	Images        []string      `json:"images"`
+	LikeCount     int           `db:"like_count" json:"like_count"`
+	CommentCount  int           `db:"comment_count" json:"comment_count"`
+	BookmarkCount int           `db:"bookmark_count" json:"bookmark_count"`
+	Created       string        `db:"created" json:"created"`
+	Updated       string        `db:"updated" json:"updated"`
+	EditedAt      string        `db:"edited_at" json:"edited_at"`
+	IsLiked       int           `db:"is_liked" json:"-"`
+	IsLikedBool   bool          `json:"is_liked"`
+	Tags          []string      `json:"tags"`
+	Poll          *PollResponse `json:"poll"`
 }
 
 func HandleGetPosts(app core.App) func(e *core.RequestEvent) error {
@@ -58,14 +62,17 @@ func HandleGetPosts(app core.App) func(e *core.RequestEvent) error {
 		err := app.DB().NewQuery(`
 			SELECT
 				p.id, p.owner, p.author_name, p.author_image,
-				p.content, p.image, p.like_count, p.comment_count,
+				p.content, p.image, COALESCE(p.images, '') as images,
+				p.like_count, p.comment_count,
 				p.bookmark_count, p.created, p.updated,
+				COALESCE(p.edited_at, '') as edited_at,
 				CASE WHEN l.id IS NOT NULL THEN 1 ELSE 0 END as is_liked
 			FROM community_posts p
 			LEFT JOIN likes l
 				ON l.target_id = p.id
 				AND l.target_type = 'post'
 				AND l.user = {:userId}
+			WHERE `+publishedCondition("p")+`
 			ORDER BY `+orderBy+`
 			LIMIT {:limit} OFFSET {:offset}
 		`).Bind(dbx.Params{
@@ -79,10 +86,23 @@ func HandleGetPosts(app core.App) func(e *core.RequestEvent) error {
 		}
 
 		var total int
-		_ = app.DB().NewQuery("SELECT COUNT(*) FROM community_posts").Row(&total)
+		_ = app.DB().NewQuery("SELECT COUNT(*) FROM community_posts p WHERE " + publishedCondition("p")).Row(&total)
+
+		postIds := make([]string, len(posts))
+		for i := range posts {
+			postIds[i] = posts[i].ID
+		}
+		polls := loadPolls(app, userId, postIds)
+
+		var collectionId string
+		if col, err := app.FindCachedCollectionByNameOrId("community_posts"); err == nil {
+			collectionId = col.Id
+		}
 
 		for i := range posts {
 			posts[i].IsLikedBool = posts[i].IsLiked == 1
+			posts[i].Poll = polls[posts[i].ID]
+			posts[i].Images = postImageURLs(app, collectionId, posts[i].ID, parseStringList(posts[i].ImagesRaw), posts[i].Image)
 		}
 
 		return e.JSON(http.StatusOK, map[string]any{
@@ -104,8 +124,10 @@ func HandleGetPost(app core.App) func(e *core.RequestEvent) error {
 		err := app.DB().NewQuery(`
 			SELECT
 				p.id, p.owner, p.author_name, p.author_image,
-				p.content, p.image, p.like_count, p.comment_count,
+				p.content, p.image, COALESCE(p.images, '') as images,
+				p.like_count, p.comment_count,
 				p.bookmark_count, p.created, p.updated,
+				COALESCE(p.edited_at, '') as edited_at,
 				CASE WHEN l.id IS NOT NULL THEN 1 ELSE 0 END as is_liked
 			FROM community_posts p
 			LEFT JOIN likes l
@@ -113,6 +135,7 @@ func HandleGetPost(app core.App) func(e *core.RequestEvent) error {
 				AND l.target_type = 'post'
 				AND l.user = {:userId}
 			WHERE p.id = {:postId}
+				AND (`+publishedCondition("p")+` OR p.owner = {:userId})
 		`).Bind(dbx.Params{
 			"userId": userId,
 			"postId": postId,
@@ -123,6 +146,11 @@ func HandleGetPost(app core.App) func(e *core.RequestEvent) error {
 		}
 
 		post.IsLikedBool = post.IsLiked == 1
+		post.Poll = loadPolls(app, userId, []string{post.ID})[post.ID]
+
+		if col, err := app.FindCachedCollectionByNameOrId("community_posts"); err == nil {
+			post.Images = postImageURLs(app, col.Id, post.ID, parseStringList(post.ImagesRaw), post.Image)
+		}
 
 		return e.JSON(http.StatusOK, post)
 	}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/dbx"
+)
+
+type UserBadge struct {
+	Badge       string `db:"badge" json:"badge"`
+	Name        string `db:"name" json:"name"`
+	Description string `db:"description" json:"description"`
+	AwardedAt   string `db:"created" json:"awarded_at"`
+}
+
+type UserProfile struct {
+	ID                  string      `db:"id" json:"id"`
+	Name                string      `db:"name" json:"name"`
+	Avatar              string      `db:"avatar" json:"avatar"`
+	Created             string      `db:"created" json:"created"`
+	FollowerCount       int         `json:"follower_count"`
+	FollowingCount      int         `json:"following_count"`
+	PostCount           int         `json:"post_count"`
+	QuestionCount       int         `json:"question_count"`
+	AnswerCount         int         `json:"answer_count"`
+	AcceptedAnswerCount int         `json:"accepted_answer_count"`
+	Reputation          int         `json:"reputation"`
+	IsFollowing         bool        `json:"is_following"`
+	Badges              []UserBadge `json:"badges"`
+}
+
+func HandleGetUserProfile(app core.App) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		authId := e.Auth.Id
+		userId := e.Request.PathValue("id")
+
+		var profile UserProfile
+		err := app.DB().NewQuery(`
+			SELECT
+				u.id,
+				COALESCE(u.name, '') as name,
+				COALESCE(u.avatar, '') as avatar,
+				u.created
+			FROM users u
+			WHERE u.id = {:userId}
+		`).Bind(dbx.Params{
+			"userId": userId,
+		}).One(&profile)
+
+		if err != nil {
+			return apis.NewNotFoundError("User not found", err)
+		}
+
+		params := dbx.Params{"userId": userId}
+
+		_ = app.DB().NewQuery("SELECT COUNT(*) FROM follows WHERE following = {:userId}").Bind(params).Row(&profile.FollowerCount)
+		_ = app.DB().NewQuery("SELECT COUNT(*) FROM follows WHERE follower = {:userId}").Bind(params).Row(&profile.FollowingCount)
+		_ = app.DB().NewQuery("SELECT COUNT(*) FROM community_posts p WHERE owner = {:userId} AND "+publishedCondition("p")).Bind(params).Row(&profile.PostCount)
+		_ = app.DB().NewQuery("SELECT COUNT(*) FROM questions q WHERE owner = {:userId} AND "+publishedCondition("q")).Bind(params).Row(&profile.QuestionCount)
+		_ = app.DB().NewQuery("SELECT COUNT(*) FROM answers WHERE author = {:userId}").Bind(params).Row(&profile.AnswerCount)
+		_ = app.DB().NewQuery("SELECT COUNT(*) FROM answers WHERE author = {:userId} AND is_accepted = true").Bind(params).Row(&profile.AcceptedAnswerCount)
+
+		profile.Reputation = userReputation(app, userId)
+
+		_ = app.DB().NewQuery(`
+			SELECT badge, name, description, created
+			FROM user_badges
+			WHERE user = {:userId}
+			ORDER BY created ASC
+		`).Bind(params).All(&profile.Badges)
+		if profile.Badges == nil {
+			profile.Badges = []UserBadge{}
+		}
+
+		if authId != userId {
+			var following int
+			_ = app.DB().NewQuery(
+				"SELECT COUNT(*) FROM follows WHERE follower = {:authId} AND following = {:userId}",
+			).Bind(dbx.Params{"authId": authId, "userId": userId}).Row(&following)
+			profile.IsFollowing = following > 0
+		}
+
+		return e.JSON(http.StatusOK, profile)
+	}
+}
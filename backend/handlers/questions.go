@@ -9,19 +9,32 @@ import (
 	"github.com/pocketbase/dbx"
 )
 
+type CategoryStats struct {
+	Category       string  `db:"category" json:"category"`
+	Total          int     `db:"total" json:"total"`
+	Answered       int     `db:"answered" json:"answered"`
+	Resolved       int     `db:"resolved" json:"resolved"`
+	AnswerRate     float64 `json:"answer_rate"`
+	ResolutionRate float64 `json:"resolution_rate"`
+}
+
 type QuestionResponse struct {
-	ID           string `db:"id" json:"id"`
-	Owner        string `db:"owner" json:"owner"`
-	Title        string `db:"title" json:"title"`
-	Content      string `db:"content" json:"content"`
-	Category     string `db:"category" json:"category"`
-	ViewCount    int    `db:"view_count" json:"view_count"`
-	CommentCount int    `db:"comment_count" json:"comment_count"`
-	CuriousCount int    `db:"curious_count" json:"curious_count"`
-	Created      string `db:"created" json:"created"`
-	Updated      string `db:"updated" json:"updated"`
-	IsCurious    int    `db:"is_curious" json:"-"`
-	IsCuriousBool bool  `json:"is_curious"`
+	ID                string `db:"id" json:"id"`
+	Owner             string `db:"owner" json:"owner"`
+	Title             string `db:"title" json:"title"`
+	Content           string `db:"content" json:"content"`
+	Category          string `db:"category" json:"category"`
+	ViewCount         int    `db:"view_count" json:"view_count"`
+	CommentCount      int    `db:"comment_count" json:"comment_count"`
+	CuriousCount      int    `db:"curious_count" json:"curious_count"`
+	Created           string `db:"created" json:"created"`
+	Updated           string `db:"updated" json:"updated"`
+	EditedAt          string `db:"edited_at" json:"edited_at"`
+	AnswerCount       int    `db:"answer_count" json:"answer_count"`
+	HasAccepted       int    `db:"has_accepted" json:"-"`
+	HasAcceptedAnswer bool   `json:"has_accepted_answer"`
+	IsCurious         int    `db:"is_curious" json:"-"`
+	IsCuriousBool     bool   `json:"is_curious"`
 }
 
 func HandleGetQuestions(app core.App) func(e *core.RequestEvent) error {
@@ -40,6 +53,7 @@ func HandleGetQuestions(app core.App) func(e *core.RequestEvent) error {
 		offset := (page - 1) * perPage
 
 		category := q.Get("category")
+		mode := q.Get("mode")
 		sort := q.Get("sort")
 		if sort == "" {
 			sort = "-created"
@@ -54,15 +68,35 @@ func HandleGetQuestions(app core.App) func(e *core.RequestEvent) error {
 			orderBy = "q.comment_count DESC, q.created DESC"
 		}
 
-		filterSQL := ""
+		filterSQL := "WHERE " + publishedCondition("q")
 		params := dbx.Params{
 			"userId": userId,
 			"limit":  perPage,
 			"offset": offset,
 		}
+		countParams := dbx.Params{}
 		if category != "" {
-			filterSQL = "WHERE q.category = {:category}"
+			filterSQL += " AND q.category = {:category}"
 			params["category"] = category
+			countParams["category"] = category
+		}
+
+		// 답변이 필요한 질문 모아보기
+		switch mode {
+		case "unanswered":
+			filterSQL += " AND NOT EXISTS (SELECT 1 FROM answers a WHERE a.question = q.id)"
+		case "unresolved":
+			days, _ := strconv.Atoi(q.Get("days"))
+			if days < 1 || days > 365 {
+				days = 3
+			}
+			filterSQL += ` AND q.created <= datetime('now', '-' || {:days} || ' days')
+				AND NOT EXISTS (SELECT 1 FROM answers a WHERE a.question = q.id AND a.is_accepted = true)`
+			params["days"] = days
+			countParams["days"] = days
+		case "":
+		default:
+			return apis.NewBadRequestError("mode must be 'unanswered' or 'unresolved'", nil)
 		}
 
 		var questions []QuestionResponse
@@ -72,6 +106,9 @@ func HandleGetQuestions(app core.App) func(e *core.RequestEvent) error {
 				q.view_count, q.comment_count,
 				COALESCE(q.curious_count, 0) as curious_count,
 				q.created, q.updated,
+				COALESCE(q.edited_at, '') as edited_at,
+				(SELECT COUNT(*) FROM answers a WHERE a.question = q.id) as answer_count,
+				EXISTS(SELECT 1 FROM answers a WHERE a.question = q.id AND a.is_accepted = true) as has_accepted,
 				CASE WHEN c.id IS NOT NULL THEN 1 ELSE 0 END as is_curious
 			FROM questions q
 			LEFT JOIN curious c
@@ -86,26 +123,30 @@ func HandleGetQuestions(app core.App) func(e *core.RequestEvent) error {
 			return apis.NewApiError(http.StatusInternalServerError, "Failed to fetch questions", err)
 		}
 
-		countQuery := "SELECT COUNT(*) FROM questions"
-		countParams := dbx.Params{}
-		if category != "" {
-			countQuery += " WHERE category = {:category}"
-			countParams["category"] = category
-		}
+		countQuery := "SELECT COUNT(*) FROM questions q " + filterSQL
 		var total int
 		_ = app.DB().NewQuery(countQuery).Bind(countParams).Row(&total)
 
 		for i := range questions {
 			questions[i].IsCuriousBool = questions[i].IsCurious == 1
+			questions[i].HasAcceptedAnswer = questions[i].HasAccepted == 1
 		}
 
-		return e.JSON(http.StatusOK, map[string]any{
+		result := map[string]any{
 			"items":      questions,
 			"page":       page,
 			"perPage":    perPage,
 			"totalItems": total,
 			"totalPages": (total + perPage - 1) / perPage,
-		})
+		}
+
+		if category != "" {
+			if stats := questionCategoryStats(app, category); len(stats) > 0 {
+				result["categoryStats"] = stats[0]
+			}
+		}
+
+		return e.JSON(http.StatusOK, result)
 	}
 }
 
@@ -121,12 +162,16 @@ func HandleGetQuestion(app core.App) func(e *core.RequestEvent) error {
 				q.view_count, q.comment_count,
 				COALESCE(q.curious_count, 0) as curious_count,
 				q.created, q.updated,
+				COALESCE(q.edited_at, '') as edited_at,
+				(SELECT COUNT(*) FROM answers a WHERE a.question = q.id) as answer_count,
+				EXISTS(SELECT 1 FROM answers a WHERE a.question = q.id AND a.is_accepted = true) as has_accepted,
 				CASE WHEN c.id IS NOT NULL THEN 1 ELSE 0 END as is_curious
 			FROM questions q
 			LEFT JOIN curious c
 				ON c.question_id = q.id
 				AND c.user_id = {:userId}
 			WHERE q.id = {:questionId}
+				AND (`+publishedCondition("q")+` OR q.owner = {:userId})
 		`).Bind(dbx.Params{
 			"userId":     userId,
 			"questionId": questionId,
@@ -137,7 +182,58 @@ func HandleGetQuestion(app core.App) func(e *core.RequestEvent) error {
 		}
 
 		question.IsCuriousBool = question.IsCurious == 1
+		question.HasAcceptedAnswer = question.HasAccepted == 1
 
 		return e.JSON(http.StatusOK, question)
 	}
 }
+
+// questionCategoryStats returns answer and resolution rates per category.
+// An empty category returns the statistics of every category.
+func questionCategoryStats(app core.App, category string) []CategoryStats {
+	filterSQL := "WHERE " + publishedCondition("q")
+	params := dbx.Params{}
+	if category != "" {
+		filterSQL += " AND q.category = {:category}"
+		params["category"] = category
+	}
+
+	var stats []CategoryStats
+	_ = app.DB().NewQuery(`
+		SELECT
+			q.category,
+			COUNT(*) as total,
+			SUM(CASE WHEN EXISTS(
+				SELECT 1 FROM answers a WHERE a.question = q.id
+			) THEN 1 ELSE 0 END) as answered,
+			SUM(CASE WHEN EXISTS(
+				SELECT 1 FROM answers a WHERE a.question = q.id AND a.is_accepted = true
+			) THEN 1 ELSE 0 END) as resolved
+		FROM questions q
+		` + filterSQL + `
+		GROUP BY q.category
+		ORDER BY total DESC
+	`).Bind(params).All(&stats)
+
+	for i := range stats {
+		if stats[i].Total > 0 {
+			stats[i].AnswerRate = float64(stats[i].Answered) / float64(stats[i].Total)
+			stats[i].ResolutionRate = float64(stats[i].Resolved) / float64(stats[i].Total)
+		}
+	}
+
+	if stats == nil {
+		stats = []CategoryStats{}
+	}
+	return stats
+}
+
+// HandleQuestionCategoryStats returns answer-rate statistics for every category.
+// GET /api/community/questions/categories
+func HandleQuestionCategoryStats(app core.App) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		return e.JSON(http.StatusOK, map[string]any{
+			"items": questionCategoryStats(app, ""),
+		})
+	}
+}
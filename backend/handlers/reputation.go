@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/dbx"
+)
+
+// 활동별 평판 점수
+const (
+	pointsAnswerAccepted  = 15
+	pointsLikeReceived    = 2
+	pointsCuriousReceived = 1
+)
+
+type LeaderboardEntry struct {
+	Rank   int    `json:"rank"`
+	UserID string `db:"user_id" json:"user_id"`
+	Name   string `db:"name" json:"name"`
+	Avatar string `db:"avatar" json:"avatar"`
+	Points int    `db:"points" json:"points"`
+}
+
+// awardReputation appends an entry to the reputation ledger.
+// The ledger is append-only: revoking points is recorded as a negative entry.
+// A revocation only applies when the ledger holds the matching award, since
+// likes, curious marks and accepted answers from before the ledger have none.
+func awardReputation(txApp core.App, userId string, points int, reason, targetId, targetType, actorId string) error {
+	if userId == "" || points == 0 {
+		return nil
+	}
+
+	if points < 0 {
+		var awarded int
+		err := txApp.DB().NewQuery(`
+			SELECT COALESCE(SUM(points), 0) FROM reputation_events
+			WHERE user = {:userId} AND reason = {:reason} AND target_id = {:targetId} AND actor = {:actorId}
+		`).Bind(dbx.Params{
+			"userId":   userId,
+			"reason":   reason,
+			"targetId": targetId,
+			"actorId":  actorId,
+		}).Row(&awarded)
+		if err != nil {
+			return err
+		}
+		if awarded <= 0 {
+			return nil
+		}
+		points = -min(-points, awarded)
+	}
+
+	collection, err := txApp.FindCollectionByNameOrId("reputation_events")
+	if err != nil {
+		return err
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("user", userId)
+	record.Set("points", points)
+	record.Set("reason", reason)
+	record.Set("target_id", targetId)
+	record.Set("target_type", targetType)
+	record.Set("actor", actorId)
+	return txApp.Save(record)
+}
+
+// contentAuthorId returns the author of a post, comment, answer or question.
+func contentAuthorId(record *core.Record) string {
+	authorId := record.GetString("author")
+	if authorId == "" {
+		authorId = record.GetString("owner")
+	}
+	return authorId
+}
+
+// userReputation sums the ledger entries of the given user.
+func userReputation(app core.App, userId string) int {
+	var total int
+	_ = app.DB().NewQuery(
+		"SELECT COALESCE(SUM(points), 0) FROM reputation_events WHERE user = {:userId}",
+	).Bind(dbx.Params{"userId": userId}).Row(&total)
+	return total
+}
+
+func HandleLeaderboard(app core.App) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		q := e.Request.URL.Query()
+
+		page, _ := strconv.Atoi(q.Get("page"))
+		if page < 1 {
+			page = 1
+		}
+		perPage, _ := strconv.Atoi(q.Get("perPage"))
+		if perPage < 1 || perPage > 100 {
+			perPage = 20
+		}
+		offset := (page - 1) * perPage
+
+		period := q.Get("period")
+		periodFilter := ""
+		switch period {
+		case "week":
+			periodFilter = "WHERE r.created >= datetime('now', '-7 days')"
+		case "month":
+			periodFilter = "WHERE r.created >= datetime('now', '-30 days')"
+		default:
+			period = "all"
+		}
+
+		var entries []LeaderboardEntry
+		err := app.DB().NewQuery(`
+			SELECT
+				r.user as user_id,
+				COALESCE(u.name, '') as name,
+				COALESCE(u.avatar, '') as avatar,
+				SUM(r.points) as points
+			FROM reputation_events r
+			LEFT JOIN users u ON u.id = r.user
+			` + periodFilter + `
+			GROUP BY r.user
+			HAVING SUM(r.points) > 0
+			ORDER BY points DESC, r.user ASC
+			LIMIT {:limit} OFFSET {:offset}
+		`).Bind(dbx.Params{
+			"limit":  perPage,
+			"offset": offset,
+		}).All(&entries)
+
+		if err != nil {
+			return apis.NewApiError(http.StatusInternalServerError, "Failed to fetch leaderboard", err)
+		}
+
+		for i := range entries {
+			entries[i].Rank = offset + i + 1
+		}
+
+		if entries == nil {
+			entries = []LeaderboardEntry{}
+		}
+
+		return e.JSON(http.StatusOK, map[string]any{
+			"items":   entries,
+			"period":  period,
+			"page":    page,
+			"perPage": perPage,
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/dbx"
+)
+
+var revisionTypeToCollection = map[string]string{
+	"post":     "community_posts",
+	"question": "questions",
+	"comment":  "comments",
+	"answer":   "answers",
+}
+
+type RevisionItem struct {
+	ID        string `db:"id" json:"id"`
+	Title     string `db:"title" json:"title"`
+	Content   string `db:"content" json:"content"`
+	WrittenAt string `db:"written_at" json:"written_at"`
+	Created   string `db:"created" json:"replaced_at"`
+}
+
+// HandleGetRevisions lists earlier versions of a post, question, comment or answer.
+// Only the content author and admins can see the history.
+func HandleGetRevisions(app core.App) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		targetType := e.Request.PathValue("type")
+		targetId := e.Request.PathValue("id")
+
+		collectionName, ok := revisionTypeToCollection[targetType]
+		if !ok {
+			return apis.NewBadRequestError("Invalid type", nil)
+		}
+
+		target, err := app.FindRecordById(collectionName, targetId)
+		if err != nil {
+			return apis.NewNotFoundError("Content not found", err)
+		}
+
+		if contentAuthorId(target) != e.Auth.Id && e.Auth.GetString("role") != "admin" {
+			return apis.NewForbiddenError("Only the author or an admin can view revisions", nil)
+		}
+
+		var revisions []RevisionItem
+		err = app.DB().NewQuery(`
+			SELECT id, title, content, written_at, created
+			FROM content_revisions
+			WHERE target_id = {:targetId} AND target_type = {:targetType}
+			ORDER BY created DESC
+		`).Bind(dbx.Params{
+			"targetId":   targetId,
+			"targetType": targetType,
+		}).All(&revisions)
+
+		if err != nil {
+			return apis.NewApiError(http.StatusInternalServerError, "Failed to fetch revisions", err)
+		}
+
+		if revisions == nil {
+			revisions = []RevisionItem{}
+		}
+
+		return e.JSON(http.StatusOK, map[string]any{
+			"items":     revisions,
+			"edited_at": target.GetString("edited_at"),
+		})
+	}
+}
@@ -20,32 +20,41 @@ func SetupFTS5(app core.App) error {
 			tokenize='unicode61'
 		)`,
 
-		`CREATE TRIGGER IF NOT EXISTS fts_insert_posts
-		AFTER INSERT ON community_posts BEGIN
+		// 임시저장/예약 게시물은 게시 시점까지 인덱싱하지 않음
+		`DROP TRIGGER IF EXISTS fts_insert_posts`,
+		`CREATE TRIGGER fts_insert_posts
+		AFTER INSERT ON community_posts
+		WHEN COALESCE(NEW.status, '') NOT IN ('draft', 'scheduled') BEGIN
 			INSERT INTO community_fts(record_id, record_type, title, content)
 			VALUES (NEW.id, 'post', '', NEW.content);
 		END`,
-		`CREATE TRIGGER IF NOT EXISTS fts_update_posts
-		AFTER UPDATE OF content ON community_posts BEGIN
+		`DROP TRIGGER IF EXISTS fts_update_posts`,
+		`CREATE TRIGGER fts_update_posts
+		AFTER UPDATE OF content, status ON community_posts BEGIN
 			DELETE FROM community_fts WHERE record_id = OLD.id AND record_type = 'post';
 			INSERT INTO community_fts(record_id, record_type, title, content)
-			VALUES (NEW.id, 'post', '', NEW.content);
+			SELECT NEW.id, 'post', '', NEW.content
+			WHERE COALESCE(NEW.status, '') NOT IN ('draft', 'scheduled');
 		END`,
 		`CREATE TRIGGER IF NOT EXISTS fts_delete_posts
 		AFTER DELETE ON community_posts BEGIN
 			DELETE FROM community_fts WHERE record_id = OLD.id AND record_type = 'post';
 		END`,
 
-		`CREATE TRIGGER IF NOT EXISTS fts_insert_questions
-		AFTER INSERT ON questions BEGIN
+		`DROP TRIGGER IF EXISTS fts_insert_questions`,
+		`CREATE TRIGGER fts_insert_questions
+		AFTER INSERT ON questions
+		WHEN COALESCE(NEW.status, '') NOT IN ('draft', 'scheduled') BEGIN
 			INSERT INTO community_fts(record_id, record_type, title, content)
 			VALUES (NEW.id, 'question', NEW.title, NEW.content);
 		END`,
-		`CREATE TRIGGER IF NOT EXISTS fts_update_questions
-		AFTER UPDATE OF title, content ON questions BEGIN
+		`DROP TRIGGER IF EXISTS fts_update_questions`,
+		`CREATE TRIGGER fts_update_questions
+		AFTER UPDATE OF title, content, status ON questions BEGIN
 			DELETE FROM community_fts WHERE record_id = OLD.id AND record_type = 'question';
 			INSERT INTO community_fts(record_id, record_type, title, content)
-			VALUES (NEW.id, 'question', NEW.title, NEW.content);
+			SELECT NEW.id, 'question', NEW.title, NEW.content
+			WHERE COALESCE(NEW.status, '') NOT IN ('draft', 'scheduled');
 		END`,
 		`CREATE TRIGGER IF NOT EXISTS fts_delete_questions
 		AFTER DELETE ON questions BEGIN
@@ -72,10 +81,12 @@ func SetupFTS5(app core.App) error {
 		app.DB().NewQuery(`
 			INSERT INTO community_fts(record_id, record_type, title, content)
 			SELECT id, 'post', '', content FROM community_posts
+			WHERE `+publishedCondition("community_posts")+`
 		`).Execute()
 		app.DB().NewQuery(`
 			INSERT INTO community_fts(record_id, record_type, title, content)
 			SELECT id, 'question', title, content FROM questions
+			WHERE `+publishedCondition("questions")+`
 		`).Execute()
 		log.Println("[FTS5] Backfill complete")
 	}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const shareSnippetLength = 150
+
+var shareTemplate = template.Must(template.New("share").Parse(`<!DOCTYPE html>
+<html lang="ko">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}} - 우물</title>
+<meta name="description" content="{{.Description}}">
+<meta property="og:type" content="article">
+<meta property="og:site_name" content="우물">
+<meta property="og:title" content="{{.Title}}">
+<meta property="og:description" content="{{.Description}}">
+<meta property="og:url" content="{{.URL}}">
+{{if .Image}}<meta property="og:image" content="{{.Image}}">
+{{end}}<meta name="twitter:card" content="{{if .Image}}summary_large_image{{else}}summary{{end}}">
+<meta name="twitter:title" content="{{.Title}}">
+<meta name="twitter:description" content="{{.Description}}">
+{{if .Image}}<meta name="twitter:image" content="{{.Image}}">
+{{end}}</head>
+<body style="font-family: Arial, sans-serif; max-width: 600px; margin: 0 auto; padding: 40px 20px;">
+<h1 style="color: #0165FE; font-size: 22px;">{{.Title}}</h1>
+{{if .Image}}<img src="{{.Image}}" alt="" style="max-width: 100%; border-radius: 12px;">
+{{end}}<p style="font-size: 16px; color: #333;">{{.Description}}</p>
+<p><a href="{{.AppURL}}" style="color: #0165FE;">우물 앱에서 전체 내용 보기</a></p>
+</body>
+</html>
+`))
+
+type sharePage struct {
+	Title       string
+	Description string
+	Image       string
+	URL         string
+	AppURL      string
+}
+
+// HandleSharePost renders a public preview page for an active post.
+// GET /share/posts/{id}
+func HandleSharePost(app core.App) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		post, err := findShareableRecord(app, "community_posts", e.Request.PathValue("id"))
+		if err != nil {
+			return apis.NewNotFoundError("Post not found", nil)
+		}
+
+		title := post.GetString("author_name")
+		if title == "" {
+			title = "회원"
+		}
+		title += "님의 게시글"
+
+		// 첫 번째 이미지를 미리보기 이미지로 사용
+		var image string
+		if urls := postImageURLs(app, post.Collection().Id, post.Id, post.GetStringSlice("images"), post.GetString("image")); len(urls) > 0 {
+			image = urls[0]
+		}
+
+		return renderSharePage(app, e, sharePage{
+			Title:       title,
+			Description: shareSnippet(post.GetString("content")),
+			Image:       image,
+		})
+	}
+}
+
+// HandleShareQuestion renders a public preview page for an active question.
+// GET /share/questions/{id}
+func HandleShareQuestion(app core.App) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		question, err := findShareableRecord(app, "questions", e.Request.PathValue("id"))
+		if err != nil {
+			return apis.NewNotFoundError("Question not found", nil)
+		}
+
+		return renderSharePage(app, e, sharePage{
+			Title:       question.GetString("title"),
+			Description: shareSnippet(question.GetString("content")),
+		})
+	}
+}
+
+// findShareableRecord returns the record only if it is publicly visible.
+// Hidden, deleted, draft and scheduled content is treated as not found.
+func findShareableRecord(app core.App, collectionName, id string) (*core.Record, error) {
+	record, err := app.FindRecordById(collectionName, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if status := record.GetString("status"); status != "" && status != "active" {
+		return nil, apis.NewNotFoundError("", nil)
+	}
+
+	return record, nil
+}
+
+func renderSharePage(app core.App, e *core.RequestEvent, page sharePage) error {
+	appURL := strings.TrimRight(app.Settings().Meta.AppURL, "/")
+	page.URL = appURL + e.Request.URL.Path
+	page.AppURL = appURL
+
+	var buf bytes.Buffer
+	if err := shareTemplate.Execute(&buf, page); err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to render page", err)
+	}
+
+	return e.HTML(http.StatusOK, buf.String())
+}
+
+func fileURL(app core.App, collectionId, recordId, filename string) string {
+	return strings.TrimRight(app.Settings().Meta.AppURL, "/") +
+		"/api/files/" + collectionId + "/" + recordId + "/" + filename
+}
+
+// shareSnippet collapses whitespace and truncates the content for previews.
+func shareSnippet(content string) string {
+	content = strings.Join(strings.Fields(content), " ")
+
+	runes := []rune(content)
+	if len(runes) > shareSnippetLength {
+		return string(runes[:shareSnippetLength]) + "…"
+	}
+	return content
+}
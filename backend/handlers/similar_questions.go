@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/dbx"
+)
+
+const (
+	maxSimilarTerms = 12
+	// 채택된 답변이 있는 질문의 점수 가중치 (bm25 점수는 낮을수록 관련도가 높음)
+	acceptedAnswerBoost = 1.5
+)
+
+// 질문 본문에 자주 붙는 한 글자 조사
+var koreanParticles = []string{"이", "가", "은", "는", "을", "를", "에", "의", "도", "로", "와", "과"}
+
+type SimilarQuestion struct {
+	ID                string  `db:"id" json:"id"`
+	Title             string  `db:"title" json:"title"`
+	Snippet           string  `db:"snippet" json:"snippet"`
+	Category          string  `db:"category" json:"category"`
+	AnswerCount       int     `db:"answer_count" json:"answer_count"`
+	HasAcceptedInt    int     `db:"has_accepted" json:"-"`
+	HasAcceptedAnswer bool    `json:"has_accepted_answer"`
+	Score             float64 `db:"score" json:"score"`
+}
+
+// HandleSimilarQuestions suggests existing questions similar to a draft
+// so users can find answered questions before posting a duplicate.
+// POST /api/community/questions/similar
+func HandleSimilarQuestions(app core.App) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		var body struct {
+			Title   string `json:"title"`
+			Content string `json:"content"`
+			Limit   int    `json:"limit"`
+		}
+		if err := json.NewDecoder(e.Request.Body).Decode(&body); err != nil {
+			return apis.NewBadRequestError("Invalid request body", err)
+		}
+
+		limit := body.Limit
+		if limit < 1 || limit > 10 {
+			limit = 5
+		}
+
+		matchQuery := buildSimilarMatchQuery(body.Title + " " + body.Content)
+		if matchQuery == "" {
+			return e.JSON(http.StatusOK, map[string]any{"items": []SimilarQuestion{}})
+		}
+
+		var items []SimilarQuestion
+		err := app.DB().NewQuery(`
+			SELECT
+				q.id, q.title,
+				snippet(community_fts, 3, '<b>', '</b>', '...', 24) as snippet,
+				COALESCE(q.category, '') as category,
+				(SELECT COUNT(*) FROM answers a WHERE a.question = q.id) as answer_count,
+				EXISTS(
+					SELECT 1 FROM answers a WHERE a.question = q.id AND a.is_accepted = true
+				) as has_accepted,
+				bm25(community_fts, 0, 0, 2.0, 1.0) * CASE
+					WHEN EXISTS(SELECT 1 FROM answers a WHERE a.question = q.id AND a.is_accepted = true)
+					THEN {:boost} ELSE 1.0
+				END as score
+			FROM community_fts
+			INNER JOIN questions q ON q.id = community_fts.record_id
+			WHERE community_fts MATCH {:query}
+				AND community_fts.record_type = 'question'
+				AND COALESCE(q.status, '') IN ('', 'active')
+			ORDER BY score
+			LIMIT {:limit}
+		`).Bind(dbx.Params{
+			"query": matchQuery,
+			"boost": acceptedAnswerBoost,
+			"limit": limit,
+		}).All(&items)
+
+		if err != nil {
+			return apis.NewApiError(http.StatusInternalServerError, "Failed to find similar questions", err)
+		}
+
+		for i := range items {
+			items[i].HasAcceptedAnswer = items[i].HasAcceptedInt == 1
+		}
+
+		if items == nil {
+			items = []SimilarQuestion{}
+		}
+
+		return e.JSON(http.StatusOK, map[string]any{
+			"items": items,
+		})
+	}
+}
+
+// buildSimilarMatchQuery turns free text into an FTS5 OR query of quoted
+// prefix terms, so user input can never break the MATCH syntax.
+func buildSimilarMatchQuery(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	seen := map[string]bool{}
+	var terms []string
+	for _, word := range words {
+		term := strings.ToLower(trimKoreanParticle(word))
+		if len([]rune(term)) < 2 || seen[term] {
+			continue
+		}
+		seen[term] = true
+		terms = append(terms, `"`+term+`"*`)
+		if len(terms) >= maxSimilarTerms {
+			break
+		}
+	}
+
+	return strings.Join(terms, " OR ")
+}
+
+// trimKoreanParticle drops a trailing one-syllable particle ("흰점이" -> "흰점")
+// so the prefix query still matches other inflections of the word.
+func trimKoreanParticle(word string) string {
+	runes := []rune(word)
+	if len(runes) < 3 || !unicode.Is(unicode.Hangul, runes[len(runes)-1]) {
+		return word
+	}
+	last := string(runes[len(runes)-1])
+	for _, p := range koreanParticles {
+		if last == p {
+			return string(runes[:len(runes)-1])
+		}
+	}
+	return word
+}
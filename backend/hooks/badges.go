@@ -0,0 +1,206 @@
+package hooks
+
+import (
+	"log"
+	"time"
+
+	"minimo-backend/notify"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/dbx"
+)
+
+// badgeRule describes a badge and the condition under which it is awarded.
+// Rules are evaluated whenever a record in one of Triggers is saved.
+// Names are localized by notify.BadgeName.
+type badgeRule struct {
+	Code        string
+	Description string
+	Triggers    []string
+	Check       func(app core.App, userId string) bool
+}
+
+var badgeRules = []badgeRule{
+	{
+		Code:        "first_accepted_answer",
+		Description: "처음으로 답변이 채택되었습니다.",
+		Triggers:    []string{"answers"},
+		Check: func(app core.App, userId string) bool {
+			return countRows(app,
+				"SELECT COUNT(*) FROM answers WHERE author = {:userId} AND is_accepted = true",
+				userId) >= 1
+		},
+	},
+	{
+		Code:        "likes_100",
+		Description: "좋아요를 100개 받았습니다.",
+		Triggers:    []string{"likes"},
+		Check: func(app core.App, userId string) bool {
+			return countRows(app, `
+				SELECT
+					(SELECT COALESCE(SUM(like_count), 0) FROM community_posts WHERE owner = {:userId}) +
+					(SELECT COALESCE(SUM(like_count), 0) FROM comments WHERE author = {:userId}) +
+					(SELECT COALESCE(SUM(like_count), 0) FROM answers WHERE author = {:userId})
+			`, userId) >= 100
+		},
+	},
+	{
+		Code:        "record_streak_30",
+		Description: "30일 연속으로 기록을 남겼습니다.",
+		Triggers:    []string{"records"},
+		Check: func(app core.App, userId string) bool {
+			return recordStreak(app, userId) >= 30
+		},
+	},
+	{
+		Code:        "aquariums_5",
+		Description: "어항을 5개 등록했습니다.",
+		Triggers:    []string{"aquariums"},
+		Check: func(app core.App, userId string) bool {
+			return countRows(app,
+				"SELECT COUNT(*) FROM aquariums WHERE owner = {:userId}",
+				userId) >= 5
+		},
+	},
+}
+
+func RegisterBadgeHooks(app core.App) {
+	app.OnRecordAfterUpdateSuccess("answers").BindFunc(func(e *core.RecordEvent) error {
+		if e.Record.GetBool("is_accepted") {
+			go evaluateBadges(app, e.Record.GetString("author"), "answers")
+		}
+		return e.Next()
+	})
+
+	app.OnRecordAfterCreateSuccess("likes").BindFunc(func(e *core.RecordEvent) error {
+		go handleLikeBadges(app, e.Record)
+		return e.Next()
+	})
+
+	app.OnRecordAfterCreateSuccess("records").BindFunc(func(e *core.RecordEvent) error {
+		go evaluateBadges(app, e.Record.GetString("owner"), "records")
+		return e.Next()
+	})
+
+	app.OnRecordAfterCreateSuccess("aquariums").BindFunc(func(e *core.RecordEvent) error {
+		go evaluateBadges(app, e.Record.GetString("owner"), "aquariums")
+		return e.Next()
+	})
+}
+
+func handleLikeBadges(app core.App, like *core.Record) {
+	collectionMap := map[string]string{
+		"post":    "community_posts",
+		"comment": "comments",
+		"answer":  "answers",
+	}
+	collectionName, ok := collectionMap[like.GetString("target_type")]
+	if !ok {
+		return
+	}
+
+	target, err := app.FindRecordById(collectionName, like.GetString("target_id"))
+	if err != nil {
+		return
+	}
+
+	authorId := target.GetString("author")
+	if authorId == "" {
+		authorId = target.GetString("owner")
+	}
+
+	evaluateBadges(app, authorId, "likes")
+}
+
+// evaluateBadges checks every rule bound to the trigger collection
+// and awards the badges the user has newly earned.
+func evaluateBadges(app core.App, userId, trigger string) {
+	if userId == "" {
+		return
+	}
+
+	for _, rule := range badgeRules {
+		if !hasTrigger(rule, trigger) || hasBadge(app, userId, rule.Code) {
+			continue
+		}
+		if rule.Check(app, userId) {
+			awardBadge(app, userId, rule)
+		}
+	}
+}
+
+func awardBadge(app core.App, userId string, rule badgeRule) {
+	collection, err := app.FindCollectionByNameOrId("user_badges")
+	if err != nil {
+		log.Printf("[Badge] Collection not found: %v", err)
+		return
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("user", userId)
+	record.Set("badge", rule.Code)
+	record.Set("name", notify.BadgeName(notify.DefaultLocale, rule.Code))
+	record.Set("description", rule.Description)
+
+	// (user, badge) 유니크 인덱스로 동시 평가 시 중복 지급 방지
+	if err := app.Save(record); err != nil {
+		return
+	}
+
+	notify.Send(app, notify.System("badge.awarded", userId, userId, "user",
+		map[string]string{"badge": rule.Code}))
+}
+
+func hasTrigger(rule badgeRule, trigger string) bool {
+	for _, t := range rule.Triggers {
+		if t == trigger {
+			return true
+		}
+	}
+	return false
+}
+
+func hasBadge(app core.App, userId, code string) bool {
+	existing, _ := app.FindFirstRecordByFilter("user_badges",
+		"user = {:user} && badge = {:badge}",
+		dbx.Params{"user": userId, "badge": code},
+	)
+	return existing != nil
+}
+
+func countRows(app core.App, query, userId string) int {
+	var count int
+	_ = app.DB().NewQuery(query).Bind(dbx.Params{"userId": userId}).Row(&count)
+	return count
+}
+
+// recordStreak returns the number of consecutive days, ending on the most
+// recent record date, on which the user wrote at least one record.
+func recordStreak(app core.App, userId string) int {
+	var days []string
+	err := app.DB().NewQuery(`
+		SELECT DISTINCT date(date) as day
+		FROM records
+		WHERE owner = {:userId} AND date != ''
+		ORDER BY day DESC
+		LIMIT 366
+	`).Bind(dbx.Params{"userId": userId}).Column(&days)
+	if err != nil || len(days) == 0 {
+		return 0
+	}
+
+	streak := 1
+	prev, err := time.Parse(time.DateOnly, days[0])
+	if err != nil {
+		return 0
+	}
+	for _, d := range days[1:] {
+		day, err := time.Parse(time.DateOnly, d)
+		if err != nil || prev.Sub(day) != 24*time.Hour {
+			break
+		}
+		streak++
+		prev = day
+	}
+	return streak
+}
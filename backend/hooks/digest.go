@@ -0,0 +1,290 @@
+package hooks
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"minimo-backend/mailer"
+	"minimo-backend/notify"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/pocketbase/dbx"
+)
+
+const (
+	digestPeriod = 7 * 24 * time.Hour
+	// 크론 실행 시각이 조금 밀려도 다음 주 발송이 빠지지 않도록 여유를 둠
+	digestMinInterval = digestPeriod - 12*time.Hour
+	digestListLimit   = 5
+)
+
+type digestAnswer struct {
+	QuestionTitle string `db:"question_title"`
+	AuthorName    string `db:"author_name"`
+}
+
+type digestSchedule struct {
+	Date         string `db:"date"`
+	Time         string `db:"time"`
+	Title        string `db:"title"`
+	AquariumName string `db:"aquarium_name"`
+}
+
+type digestPost struct {
+	Snippet      string `db:"snippet"`
+	LikeCount    int    `db:"like_count"`
+	CommentCount int    `db:"comment_count"`
+}
+
+// digestData is the content of one weekly digest email.
+type digestData struct {
+	Name           string
+	Answers        []digestAnswer
+	AnswerCount    int
+	LikeCount      int
+	Followers      []string
+	FollowerCount  int
+	Schedules      []digestSchedule
+	Trending       []digestPost
+	UnsubscribeURL string
+}
+
+func (d *digestData) empty() bool {
+	return d.AnswerCount == 0 && d.LikeCount == 0 && d.FollowerCount == 0 &&
+		len(d.Schedules) == 0 && len(d.Trending) == 0
+}
+
+func RegisterDigestJobs(app core.App) {
+	// 매주 월요일 00:00 UTC (한국 시간 오전 9시) 주간 소식 발송
+	app.Cron().MustAdd("weekly_digest", "0 0 * * 1", func() {
+		sendWeeklyDigests(app)
+	})
+
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		se.Router.GET("/api/digest/unsubscribe", handleDigestUnsubscribeConfirm(app))
+		se.Router.POST("/api/digest/unsubscribe", handleDigestUnsubscribe(app))
+		return se.Next()
+	})
+}
+
+// sendWeeklyDigests emails every opted-in user whose last digest is older than
+// digestMinInterval, so a restart or a second run never sends twice.
+func sendWeeklyDigests(app core.App) {
+	if mailer.Default() == nil {
+		log.Println("[Digest] No mailer configured, skipping weekly digest")
+		return
+	}
+
+	cutoff := types.NowDateTime().Add(-digestMinInterval)
+	prefs, err := app.FindRecordsByFilter("notification_preferences",
+		"email_digest = true && (digest_sent_at = '' || digest_sent_at < {:cutoff})",
+		"", 0, 0,
+		dbx.Params{"cutoff": cutoff.String()},
+	)
+	if err != nil {
+		log.Printf("[Digest] Failed to load subscribers: %v", err)
+		return
+	}
+
+	trending := digestTrendingPosts(app)
+
+	sent := 0
+	for _, pref := range prefs {
+		if err := sendDigest(app, pref, trending); err != nil {
+			log.Printf("[Digest] Failed to send digest to user %s: %v", pref.GetString("user"), err)
+			continue
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		log.Printf("[Digest] Sent %d weekly digests", sent)
+	}
+}
+
+func sendDigest(app core.App, pref *core.Record, trending []digestPost) error {
+	user, err := app.FindRecordById("users", pref.GetString("user"))
+	if err != nil || user.Email() == "" {
+		return nil
+	}
+
+	// 지난 발송 이후의 활동만 모음 (최대 1주)
+	since := types.NowDateTime().Add(-digestPeriod)
+	if last := pref.GetDateTime("digest_sent_at"); !last.IsZero() && last.After(since) {
+		since = last
+	}
+
+	data := collectDigest(app, user.Id, since)
+	data.Trending = trending
+	if data.empty() {
+		return nil
+	}
+
+	data.Name = user.GetString("name")
+	if pref.GetString("digest_token") == "" {
+		pref.Set("digest_token", security.RandomString(32))
+	}
+	data.UnsubscribeURL = strings.TrimRight(app.Settings().Meta.AppURL, "/") +
+		"/api/digest/unsubscribe?token=" + url.QueryEscape(pref.GetString("digest_token"))
+
+	subject, html, err := mailer.Render(notify.LocaleOf(user), "digest", data)
+	if err != nil {
+		return err
+	}
+
+	err = mailer.Send(&mailer.Message{
+		To:      []string{user.Email()},
+		Subject: subject,
+		HTML:    html,
+		Headers: map[string]string{
+			"List-Unsubscribe": "<" + data.UnsubscribeURL + ">",
+			// 메일 앱의 원클릭 수신 거부 (RFC 8058): 같은 URL로 POST
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	pref.Set("digest_sent_at", types.NowDateTime())
+	return app.Save(pref)
+}
+
+// collectDigest gathers the user's activity since the given time.
+func collectDigest(app core.App, userId string, since types.DateTime) *digestData {
+	params := dbx.Params{"userId": userId, "since": since.String(), "limit": digestListLimit}
+	data := &digestData{}
+
+	_ = app.DB().NewQuery(`
+		SELECT COUNT(*) FROM answers a
+		JOIN questions q ON q.id = a.question
+		WHERE q.owner = {:userId} AND a.author != {:userId} AND a.created >= {:since}
+	`).Bind(params).Row(&data.AnswerCount)
+	_ = app.DB().NewQuery(`
+		SELECT q.title as question_title, COALESCE(u.name, '') as author_name
+		FROM answers a
+		JOIN questions q ON q.id = a.question
+		LEFT JOIN users u ON u.id = a.author
+		WHERE q.owner = {:userId} AND a.author != {:userId} AND a.created >= {:since}
+		ORDER BY a.created DESC
+		LIMIT {:limit}
+	`).Bind(params).All(&data.Answers)
+
+	_ = app.DB().NewQuery(`
+		SELECT COUNT(*) FROM likes l
+		WHERE l.user != {:userId} AND l.created >= {:since} AND (
+			(l.target_type = 'post' AND EXISTS (SELECT 1 FROM community_posts p WHERE p.id = l.target_id AND p.owner = {:userId}))
+			OR (l.target_type = 'comment' AND EXISTS (SELECT 1 FROM comments c WHERE c.id = l.target_id AND c.author = {:userId}))
+			OR (l.target_type = 'answer' AND EXISTS (SELECT 1 FROM answers a WHERE a.id = l.target_id AND a.author = {:userId}))
+		)
+	`).Bind(params).Row(&data.LikeCount)
+
+	_ = app.DB().NewQuery(
+		"SELECT COUNT(*) FROM follows WHERE following = {:userId} AND created >= {:since}",
+	).Bind(params).Row(&data.FollowerCount)
+	_ = app.DB().NewQuery(`
+		SELECT COALESCE(u.name, '') FROM follows f
+		JOIN users u ON u.id = f.follower
+		WHERE f.following = {:userId} AND f.created >= {:since} AND u.name != ''
+		ORDER BY f.created DESC
+		LIMIT {:limit}
+	`).Bind(params).Column(&data.Followers)
+
+	// 앞으로 7일간 완료되지 않은 어항 일정
+	today := time.Now().UTC().Format(time.DateOnly)
+	nextWeek := time.Now().UTC().Add(digestPeriod).Format(time.DateOnly)
+	_ = app.DB().NewQuery(`
+		SELECT substr(date, 1, 10) as date, COALESCE(time, '') as time, title, COALESCE(aquarium_name, '') as aquarium_name
+		FROM schedules
+		WHERE owner = {:userId} AND is_completed = false AND date >= {:from} AND date < {:to}
+		ORDER BY date, time
+		LIMIT 10
+	`).Bind(dbx.Params{"userId": userId, "from": today, "to": nextWeek}).All(&data.Schedules)
+
+	return data
+}
+
+// digestTrendingPosts returns the most popular posts of the past week,
+// scored like the trending feed.
+func digestTrendingPosts(app core.App) []digestPost {
+	var posts []digestPost
+	_ = app.DB().NewQuery(`
+		SELECT substr(content, 1, 80) as snippet, like_count, comment_count
+		FROM community_posts p
+		WHERE created >= datetime('now', '-7 days')
+			AND COALESCE(p.status, '') IN ('', 'active')
+		ORDER BY (like_count * 3.0 + comment_count * 2.0 + bookmark_count * 1.5) DESC
+		LIMIT {:limit}
+	`).Bind(dbx.Params{"limit": digestListLimit}).All(&posts)
+	return posts
+}
+
+// handleDigestUnsubscribeConfirm shows the page linked from the email, which
+// asks before unsubscribing so link scanners opening it change nothing.
+// GET /api/digest/unsubscribe?token=
+func handleDigestUnsubscribeConfirm(app core.App) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		pref, err := findDigestPreference(app, e.Request.URL.Query().Get("token"))
+		if err != nil {
+			return err
+		}
+
+		_, html, err := mailer.Render(digestLocale(app, pref), "unsubscribe_confirm", map[string]string{
+			"Token": pref.GetString("digest_token"),
+		})
+		if err != nil {
+			return apis.NewApiError(http.StatusInternalServerError, "Failed to render page", err)
+		}
+		return e.HTML(http.StatusOK, html)
+	}
+}
+
+// handleDigestUnsubscribe turns off the weekly digest for the token owner.
+// It is posted by the confirmation page, or by mail clients as a one-click
+// unsubscribe with the token in the query, so it needs no auth.
+// POST /api/digest/unsubscribe?token=
+func handleDigestUnsubscribe(app core.App) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		pref, err := findDigestPreference(app, e.Request.FormValue("token"))
+		if err != nil {
+			return err
+		}
+
+		pref.Set("email_digest", false)
+		if err := app.Save(pref); err != nil {
+			return apis.NewApiError(http.StatusInternalServerError, "Failed to unsubscribe", err)
+		}
+
+		_, html, err := mailer.Render(digestLocale(app, pref), "unsubscribed", nil)
+		if err != nil {
+			return apis.NewApiError(http.StatusInternalServerError, "Failed to render page", err)
+		}
+		return e.HTML(http.StatusOK, html)
+	}
+}
+
+func findDigestPreference(app core.App, token string) (*core.Record, error) {
+	if token == "" {
+		return nil, apis.NewBadRequestError("token is required", nil)
+	}
+
+	pref, err := app.FindFirstRecordByData("notification_preferences", "digest_token", token)
+	if err != nil {
+		return nil, apis.NewNotFoundError("Invalid unsubscribe link", nil)
+	}
+	return pref, nil
+}
+
+// digestLocale returns the language of the preference owner.
+func digestLocale(app core.App, pref *core.Record) string {
+	if user, err := app.FindRecordById("users", pref.GetString("user")); err == nil {
+		return notify.LocaleOf(user)
+	}
+	return notify.DefaultLocale
+}
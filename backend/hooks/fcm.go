@@ -3,15 +3,26 @@ package hooks
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"log"
 	"os"
+	"slices"
+	"strconv"
 	"sync"
+	"time"
+
+	"minimo-backend/notify"
 
 	firebase "firebase.google.com/go/v4"
 	"firebase.google.com/go/v4/messaging"
+	"github.com/pocketbase/pocketbase/core"
 	"google.golang.org/api/option"
+	"github.com/pocketbase/dbx"
 )
 
+// 방해 금지 시간에는 푸시를 보내지 않고 버리는 알림 종류 (그 외는 시간이 끝난 뒤 발송)
+var quietDropTypes = []string{"like", "follow"}
+
 var (
 	fcmClient     *messaging.Client
 	fcmClientOnce sync.Once
@@ -55,7 +66,8 @@ func getFCMClient() *messaging.Client {
 }
 
 // sendDirectFCMPush sends a push notification directly via Firebase Admin SDK.
-func sendDirectFCMPush(token, title, body string, data map[string]string) error {
+// badge is the unread count shown on the app icon.
+func sendDirectFCMPush(token, title, body string, data map[string]string, badge int) error {
 	client := getFCMClient()
 	if client == nil {
 		return nil // push disabled
@@ -67,12 +79,12 @@ func sendDirectFCMPush(token, title, body string, data map[string]string) error
 			Title: title,
 			Body:  body,
 		},
-		Data: data,
+		Data: withBadge(data, badge),
 		APNS: &messaging.APNSConfig{
 			Payload: &messaging.APNSPayload{
 				Aps: &messaging.Aps{
 					Sound: "default",
-					Badge: intPtr(1),
+					Badge: intPtr(badge),
 				},
 			},
 		},
@@ -94,6 +106,152 @@ func sendDirectFCMPush(token, title, body string, data map[string]string) error
 	return nil
 }
 
+// sendSilentFCMPush updates the app badge without showing an alert.
+func sendSilentFCMPush(token string, badge int) error {
+	client := getFCMClient()
+	if client == nil {
+		return nil // push disabled
+	}
+
+	msg := &messaging.Message{
+		Token: token,
+		Data:  withBadge(map[string]string{"type": "badge"}, badge),
+		APNS: &messaging.APNSConfig{
+			Headers: map[string]string{
+				"apns-push-type": "background",
+				"apns-priority":  "5",
+			},
+			Payload: &messaging.APNSPayload{
+				Aps: &messaging.Aps{
+					ContentAvailable: true,
+					Badge:            intPtr(badge),
+				},
+			},
+		},
+		Android: &messaging.AndroidConfig{
+			Priority: "normal",
+		},
+	}
+
+	if _, err := client.Send(context.Background(), msg); err != nil {
+		log.Printf("[FCM] Silent send error: %v", err)
+		return err
+	}
+	return nil
+}
+
+// quietHoursHold checks the user's quiet hours for a push of notifType.
+// It returns the end of the current quiet window when the push must be held,
+// a zero time when it can be sent now, and drop=true when it is discarded.
+func quietHoursHold(app core.App, userId, notifType string, now time.Time) (time.Time, bool) {
+	prefs, err := app.FindFirstRecordByFilter("notification_preferences",
+		"user = {:user}", dbx.Params{"user": userId})
+	if err != nil || !prefs.GetBool("quiet_hours_enabled") {
+		return time.Time{}, false
+	}
+
+	start, okStart := parseClock(prefs.GetString("quiet_start"))
+	end, okEnd := parseClock(prefs.GetString("quiet_end"))
+	if !okStart || !okEnd || start == end {
+		return time.Time{}, false
+	}
+
+	loc, err := time.LoadLocation(prefs.GetString("timezone"))
+	if err != nil || prefs.GetString("timezone") == "" {
+		loc, _ = time.LoadLocation(notify.DefaultTimezone)
+	}
+
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+
+	var quiet bool
+	if start < end {
+		quiet = minute >= start && minute < end
+	} else {
+		// 22:00 ~ 07:00 처럼 자정을 넘는 구간
+		quiet = minute >= start || minute < end
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	if slices.Contains(quietDropTypes, notifType) {
+		return time.Time{}, true
+	}
+
+	until := time.Date(local.Year(), local.Month(), local.Day(), end/60, end%60, 0, 0, loc)
+	if !until.After(local) {
+		until = until.AddDate(0, 0, 1)
+	}
+	return until, false
+}
+
+// parseClock converts "HH:MM" to minutes since midnight.
+func parseClock(value string) (int, bool) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// sendBadgeUpdate queues a silent push that syncs the app badge on every
+// device of the user, e.g. after notifications were marked as read.
+func sendBadgeUpdate(app core.App, userId string) {
+	enqueuePush(app, userId, "", pushKindSilent, "", "", nil)
+}
+
+// withBadge returns a copy of data with the badge count for Android clients,
+// which have no APNS badge and update the launcher count themselves.
+func withBadge(data map[string]string, badge int) map[string]string {
+	result := make(map[string]string, len(data)+1)
+	for k, v := range data {
+		result[k] = v
+	}
+	result["badge"] = strconv.Itoa(badge)
+	return result
+}
+
+// userPushTargets returns the tokens of all devices of the user, plus the
+// single users.fcm_token saved by app versions without device registration.
+func userPushTargets(app core.App, userId string) []pushTarget {
+	var targets []pushTarget
+	_ = app.DB().NewQuery(
+		"SELECT token, platform FROM devices WHERE user = {:userId} ORDER BY last_seen DESC",
+	).Bind(dbx.Params{"userId": userId}).All(&targets)
+
+	if user, err := app.FindRecordById("users", userId); err == nil {
+		legacy := user.GetString("fcm_token")
+		known := slices.ContainsFunc(targets, func(t pushTarget) bool { return t.Token == legacy })
+		if legacy != "" && !known {
+			targets = append(targets, pushTarget{Token: legacy})
+		}
+	}
+
+	return targets
+}
+
+// isStaleTokenError reports errors meaning the token will never work again.
+// Invalid argument errors are not included: they are also returned for bad
+// payloads, and pruning on them would delete working devices.
+func isStaleTokenError(err error) bool {
+	return errors.Is(err, errStaleSubscription) ||
+		messaging.IsUnregistered(err) ||
+		messaging.IsRegistrationTokenNotRegistered(err) ||
+		messaging.IsSenderIDMismatch(err)
+}
+
+func pruneToken(app core.App, userId, token string) {
+	_, _ = app.DB().NewQuery(
+		"DELETE FROM devices WHERE token = {:token}",
+	).Bind(dbx.Params{"token": token}).Execute()
+	_, _ = app.DB().NewQuery(
+		"UPDATE users SET fcm_token = '' WHERE id = {:userId} AND fcm_token = {:token}",
+	).Bind(dbx.Params{"userId": userId, "token": token}).Execute()
+
+	log.Printf("[Push] Pruned stale token for user %s", userId)
+}
+
 func intPtr(i int) *int {
 	return &i
 }
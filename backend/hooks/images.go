@@ -0,0 +1,206 @@
+package hooks
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/disintegration/imaging"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+)
+
+// postImageFields are the community_posts file fields sanitized on upload.
+var postImageFields = []string{"image", "images"}
+
+// errUnsupportedImage is returned for uploads that are not JPEG, PNG or WebP
+// (e.g. HEIC), whose metadata cannot be stripped.
+var errUnsupportedImage = errors.New("unsupported image format")
+
+// errImageTooLarge is returned for uploads above the file field's MaxSize or
+// above maxImagePixels once decoded.
+var errImageTooLarge = errors.New("image too large")
+
+// maxImagePixels caps the decoded size of an upload (about 40MP), since a
+// small compressed file can expand to gigabytes when fully decoded.
+const maxImagePixels = 40_000_000
+
+func RegisterImageHooks(app core.App) {
+	app.OnRecordCreateRequest("community_posts").BindFunc(func(e *core.RecordRequestEvent) error {
+		if err := stripUploadedImages(e.Record); err != nil {
+			return err
+		}
+		return e.Next()
+	})
+
+	app.OnRecordUpdateRequest("community_posts").BindFunc(func(e *core.RecordRequestEvent) error {
+		if err := stripUploadedImages(e.Record); err != nil {
+			return err
+		}
+		return e.Next()
+	})
+}
+
+// stripUploadedImages replaces every newly uploaded image of the record with a
+// copy without EXIF/GPS metadata, keeping the order of the field values.
+// Uploads that cannot be sanitized are rejected so no metadata is ever stored.
+func stripUploadedImages(record *core.Record) error {
+	for _, field := range postImageFields {
+		// 필드 검증(MaxSize)은 이 훅 이후에 실행되므로 디코딩 전에 직접 확인
+		maxSize := core.DefaultFileFieldMaxSize
+		if f, ok := record.Collection().Fields.GetByName(field).(*core.FileField); ok && f.MaxSize > 0 {
+			maxSize = f.MaxSize
+		}
+
+		switch value := record.GetRaw(field).(type) {
+		case *filesystem.File:
+			clean, err := stripFile(value, maxSize)
+			if err != nil {
+				return imageUploadError(value, err)
+			}
+			record.Set(field, clean)
+		case []any:
+			files := make([]any, len(value))
+			for i, v := range value {
+				f, ok := v.(*filesystem.File)
+				if !ok {
+					files[i] = v
+					continue
+				}
+				clean, err := stripFile(f, maxSize)
+				if err != nil {
+					return imageUploadError(f, err)
+				}
+				files[i] = clean
+			}
+			record.Set(field, files)
+		}
+	}
+	return nil
+}
+
+func imageUploadError(f *filesystem.File, err error) error {
+	log.Printf("[Image] Rejected upload %s: %v", f.OriginalName, err)
+	if errors.Is(err, errUnsupportedImage) {
+		return apis.NewBadRequestError("JPEG, PNG, WebP 이미지만 업로드할 수 있습니다.", nil)
+	}
+	if errors.Is(err, errImageTooLarge) {
+		return apis.NewBadRequestError("이미지가 너무 큽니다.", nil)
+	}
+	return apis.NewBadRequestError("이미지를 처리할 수 없습니다.", nil)
+}
+
+// stripFile returns a sanitized copy of f, or errImageTooLarge when f is
+// larger than maxSize bytes.
+func stripFile(f *filesystem.File, maxSize int64) (*filesystem.File, error) {
+	if f.Size > maxSize {
+		return nil, errImageTooLarge
+	}
+
+	r, err := f.Reader.Open()
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	r.Close()
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, errImageTooLarge
+	}
+
+	stripped, err := stripImageMetadata(data)
+	if err != nil {
+		return nil, err
+	}
+
+	clean, err := filesystem.NewFileFromBytes(stripped, f.OriginalName)
+	if err != nil {
+		return nil, err
+	}
+	clean.Name = f.Name
+	return clean, nil
+}
+
+// stripImageMetadata removes EXIF, GPS and XMP metadata from JPEG, PNG and WebP
+// images. JPEGs are re-encoded with their EXIF orientation applied so that
+// photos taken in portrait mode keep displaying upright. Other formats are
+// rejected with errUnsupportedImage, and images above maxImagePixels with
+// errImageTooLarge before they are decoded.
+func stripImageMetadata(data []byte) ([]byte, error) {
+	var format imaging.Format
+
+	switch http.DetectContentType(data) {
+	case "image/jpeg":
+		format = imaging.JPEG
+	case "image/png":
+		format = imaging.PNG
+	case "image/webp":
+		return stripWebPMetadata(data)
+	default:
+		return nil, errUnsupportedImage
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if int64(config.Width)*int64(config.Height) > maxImagePixels {
+		return nil, errImageTooLarge
+	}
+
+	img, err := imaging.Decode(bytes.NewReader(data), imaging.AutoOrientation(true))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := imaging.Encode(&buf, img, format, imaging.JPEGQuality(90)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// stripWebPMetadata drops the EXIF and XMP chunks of a WebP container
+// and clears the matching flags of the VP8X header.
+func stripWebPMetadata(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, errors.New("invalid webp container")
+	}
+
+	out := make([]byte, 12, len(data))
+	copy(out, data[:12])
+
+	for pos := 12; pos+8 <= len(data); {
+		fourCC := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		end := pos + 8 + size + size%2 // 청크는 짝수 바이트로 패딩됨
+		if end > len(data) {
+			return nil, errors.New("truncated webp chunk")
+		}
+
+		switch fourCC {
+		case "EXIF", "XMP ":
+			// skip
+		case "VP8X":
+			chunk := append([]byte(nil), data[pos:end]...)
+			if len(chunk) > 8 {
+				chunk[8] &^= 0x08 | 0x04 // EXIF, XMP 플래그 해제
+			}
+			out = append(out, chunk...)
+		default:
+			out = append(out, data[pos:end]...)
+		}
+
+		pos = end
+	}
+
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(out)-8))
+	return out, nil
+}
@@ -0,0 +1,20 @@
+package hooks
+
+import (
+	"minimo-backend/notify"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// registerLocaleHooks stores a supported locale on every new user. Clients
+// may send locale at sign-up; otherwise it comes from Accept-Language.
+func registerLocaleHooks(app core.App) {
+	app.OnRecordCreateRequest("users").BindFunc(func(e *core.RecordRequestEvent) error {
+		locale := e.Record.GetString("locale")
+		if locale == "" {
+			locale = e.Request.Header.Get("Accept-Language")
+		}
+		e.Record.Set("locale", notify.NormalizeLocale(locale))
+		return e.Next()
+	})
+}
@@ -1,17 +1,40 @@
 package hooks
 
 import (
-	"log"
+	"minimo-backend/notify"
 
 	"github.com/pocketbase/pocketbase/core"
 )
 
 func RegisterNotificationHooks(app core.App) {
+	registerLocaleHooks(app)
+	registerRealtimeHooks(app)
+
+	notify.OnUnreadChanged(func(app core.App, userId string) {
+		sendBadgeUpdate(app, userId)
+	})
+
+	notify.OnPush(func(app core.App, n *notify.Notification, notificationId string) {
+		enqueueAlertPush(app, n.Recipient, notificationId, string(n.Type), n.TargetID, n.TargetType, n.Title, n.Message)
+	})
+
 	app.OnRecordAfterCreateSuccess("notifications").BindFunc(func(e *core.RecordEvent) error {
-		go sendFCMPush(app, e.Record)
+		enqueueNotificationPush(e.App, e.Record)
 		return e.Next()
 	})
 
+	// 알림 하나를 읽음 처리하면 다른 기기의 앱 배지도 동기화 (일괄 처리는 OnUnreadChanged)
+	app.OnRecordUpdate("notifications").BindFunc(func(e *core.RecordEvent) error {
+		wasUnread := !e.Record.Original().GetBool("is_read")
+		if err := e.Next(); err != nil {
+			return err
+		}
+		if wasUnread && e.Record.GetBool("is_read") {
+			sendBadgeUpdate(e.App, e.Record.GetString("user"))
+		}
+		return nil
+	})
+
 	app.OnRecordAfterCreateSuccess("answers").BindFunc(func(e *core.RecordEvent) error {
 		go handleAnswerNotification(app, e.Record)
 		return e.Next()
@@ -33,36 +56,39 @@ func RegisterNotificationHooks(app core.App) {
 	})
 }
 
-func sendFCMPush(app core.App, notification *core.Record) {
-	userId := notification.GetString("user")
+// enqueueNotificationPush queues the push for a notification on all devices
+// of its user, unless the user turned off pushes for its type.
+func enqueueNotificationPush(app core.App, notification *core.Record) {
+	enqueueAlertPush(app,
+		notification.GetString("user"),
+		notification.Id,
+		notification.GetString("type"),
+		notification.GetString("target_id"),
+		notification.GetString("target_type"),
+		notification.GetString("title"),
+		notification.GetString("message"),
+	)
+}
+
+// enqueueAlertPush is enqueueNotificationPush for notifications that were not
+// stored as a record of their own; notificationId may be empty.
+func enqueueAlertPush(app core.App, userId, notificationId, notifType, targetId, targetType, title, message string) {
 	if userId == "" {
 		return
 	}
 
-	user, err := app.FindRecordById("users", userId)
-	if err != nil {
-		return
-	}
-	fcmToken := user.GetString("fcm_token")
-	if fcmToken == "" {
+	if !notify.Allowed(app, userId, notifType, notify.ChannelPush) {
 		return
 	}
 
 	data := map[string]string{
-		"type":            notification.GetString("type"),
-		"target_id":       notification.GetString("target_id"),
-		"target_type":     notification.GetString("target_type"),
-		"notification_id": notification.Id,
+		"type":            notifType,
+		"target_id":       targetId,
+		"target_type":     targetType,
+		"notification_id": notificationId,
 	}
 
-	if err := sendDirectFCMPush(
-		fcmToken,
-		notification.GetString("title"),
-		notification.GetString("message"),
-		data,
-	); err != nil {
-		log.Printf("[FCM] Push failed for user %s: %v", userId, err)
-	}
+	enqueuePush(app, userId, notificationId, pushKindAlert, title, message, data)
 }
 
 func handleAnswerNotification(app core.App, answer *core.Record) {
@@ -82,9 +108,7 @@ func handleAnswerNotification(app core.App, answer *core.Record) {
 		return
 	}
 
-	createNotification(app, authorId, "answer", "새 답변",
-		"회원님의 질문에 새 답변이 달렸습니다.",
-		questionId, "question", answererID)
+	notify.Send(app, notify.Answer(authorId, answererID, questionId))
 }
 
 func handleCommentNotification(app core.App, comment *core.Record) {
@@ -104,9 +128,7 @@ func handleCommentNotification(app core.App, comment *core.Record) {
 		return
 	}
 
-	createNotification(app, authorId, "comment", "새 댓글",
-		"회원님의 게시글에 새 댓글이 달렸습니다.",
-		postId, "post", commenterId)
+	notify.Send(app, notify.Comment(authorId, commenterId, postId))
 }
 
 func handleFollowNotification(app core.App, follow *core.Record) {
@@ -116,18 +138,7 @@ func handleFollowNotification(app core.App, follow *core.Record) {
 		return
 	}
 
-	follower, err := app.FindRecordById("users", followerId)
-	if err != nil {
-		return
-	}
-	followerName := follower.GetString("name")
-	if followerName == "" {
-		followerName = "회원"
-	}
-
-	createNotification(app, followingId, "follow", "새 팔로워",
-		followerName+"님이 회원님을 팔로우합니다.",
-		followerId, "user", followerId)
+	notify.Send(app, notify.Follow(followingId, followerId))
 }
 
 func handleLikeDelete(app core.App, like *core.Record) {
@@ -160,25 +171,3 @@ func handleLikeDelete(app core.App, like *core.Record) {
 	target.Set("like_count", newCount)
 	_ = app.Save(target)
 }
-
-func createNotification(app core.App, userId, notifType, title, message, targetId, targetType, actorId string) {
-	collection, err := app.FindCollectionByNameOrId("notifications")
-	if err != nil {
-		log.Printf("[Notification] Collection not found: %v", err)
-		return
-	}
-
-	record := core.NewRecord(collection)
-	record.Set("user", userId)
-	record.Set("type", notifType)
-	record.Set("title", title)
-	record.Set("message", message)
-	record.Set("target_id", targetId)
-	record.Set("target_type", targetType)
-	record.Set("is_read", false)
-	record.Set("actor", actorId)
-
-	if err := app.Save(record); err != nil {
-		log.Printf("[Notification] Failed to create: %v", err)
-	}
-}
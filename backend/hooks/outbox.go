@@ -0,0 +1,205 @@
+package hooks
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"minimo-backend/notify"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/pocketbase/dbx"
+)
+
+const (
+	pushKindAlert  = "alert"
+	pushKindSilent = "silent"
+
+	// 이 횟수만큼 실패하면 더 이상 재시도하지 않음 (dead)
+	pushMaxAttempts = 6
+	pushBaseBackoff = 30 * time.Second
+	pushMaxBackoff  = time.Hour
+	pushBatchSize   = 100
+)
+
+// 푸시 워커는 한 번에 하나만 실행 (크론과 즉시 실행이 겹치지 않도록)
+var pushWorkerMu sync.Mutex
+
+func RegisterPushWorker(app core.App) {
+	// 매분 재시도 대상 푸시 처리
+	app.Cron().MustAdd("push_outbox", "* * * * *", func() {
+		processPushOutbox(app)
+	})
+}
+
+// enqueuePush stores one outbox row per device token of the user and kicks
+// the worker. Delivery happens asynchronously and survives restarts.
+func enqueuePush(app core.App, userId, notificationId, kind, title, body string, data map[string]string) {
+	// 방해 금지 시간대: 알림 푸시는 시간대가 끝날 때까지 보류하거나 버림 (배지 갱신은 제외)
+	nextAttemptAt := types.NowDateTime()
+	if kind == pushKindAlert {
+		until, drop := quietHoursHold(app, userId, data["type"], time.Now())
+		if drop {
+			return
+		}
+		if !until.IsZero() {
+			nextAttemptAt, _ = types.ParseDateTime(until)
+		}
+	}
+
+	collection, err := app.FindCachedCollectionByNameOrId("push_outbox")
+	if err != nil {
+		log.Printf("[FCM] Outbox collection not found: %v", err)
+		return
+	}
+
+	queued := 0
+	for _, target := range userPushTargets(app, userId) {
+		if !providerFor(target.Platform).Enabled() {
+			continue // 해당 플랫폼 푸시가 설정되지 않은 서버
+		}
+
+		item := core.NewRecord(collection)
+		item.Set("user", userId)
+		item.Set("token", target.Token)
+		item.Set("platform", target.Platform)
+		item.Set("kind", kind)
+		item.Set("notification_id", notificationId)
+		item.Set("title", title)
+		item.Set("body", body)
+		item.Set("data", data)
+		item.Set("status", "pending")
+		item.Set("attempts", 0)
+		item.Set("next_attempt_at", nextAttemptAt)
+
+		if err := app.Save(item); err != nil {
+			log.Printf("[FCM] Failed to enqueue push for user %s: %v", userId, err)
+			continue
+		}
+		queued++
+	}
+
+	if queued > 0 && notificationId != "" {
+		setNotificationPushStatus(app, notificationId, "pending")
+	}
+
+	if queued > 0 {
+		go processPushOutbox(app)
+	}
+}
+
+// processPushOutbox delivers every due outbox item, batch by batch.
+func processPushOutbox(app core.App) {
+	if !pushWorkerMu.TryLock() {
+		return
+	}
+	defer pushWorkerMu.Unlock()
+
+	for {
+		items, err := app.FindRecordsByFilter("push_outbox",
+			"status = 'pending' && next_attempt_at <= {:now}",
+			"next_attempt_at", pushBatchSize, 0,
+			dbx.Params{"now": types.NowDateTime().String()},
+		)
+		if err != nil || len(items) == 0 {
+			return
+		}
+
+		for _, item := range items {
+			deliverOutboxItem(app, item)
+		}
+
+		if len(items) < pushBatchSize {
+			return
+		}
+	}
+}
+
+func deliverOutboxItem(app core.App, item *core.Record) {
+	userId := item.GetString("user")
+	token := item.GetString("token")
+
+	// 배지는 발송 시점의 읽지 않은 알림 수로 설정
+	badge := notify.UnreadCount(app, userId)
+
+	msg := pushMessage{
+		Title:  item.GetString("title"),
+		Body:   item.GetString("body"),
+		Badge:  badge,
+		Silent: item.GetString("kind") == pushKindSilent,
+	}
+	_ = item.UnmarshalJSONField("data", &msg.Data)
+
+	err := providerFor(item.GetString("platform")).Send(token, msg)
+
+	attempts := item.GetInt("attempts") + 1
+	item.Set("attempts", attempts)
+
+	switch {
+	case err == nil:
+		item.Set("status", "sent")
+		item.Set("sent_at", types.NowDateTime())
+		item.Set("last_error", "")
+	case isStaleTokenError(err):
+		pruneToken(app, userId, token)
+		item.Set("status", "dead")
+		item.Set("last_error", err.Error())
+	case attempts >= pushMaxAttempts:
+		log.Printf("[FCM] Giving up push %s after %d attempts: %v", item.Id, attempts, err)
+		item.Set("status", "dead")
+		item.Set("last_error", err.Error())
+	default:
+		item.Set("next_attempt_at", types.NowDateTime().Add(pushBackoff(attempts)))
+		item.Set("last_error", err.Error())
+	}
+
+	if err := app.Save(item); err != nil {
+		log.Printf("[FCM] Failed to update outbox item %s: %v", item.Id, err)
+		return
+	}
+
+	if notificationId := item.GetString("notification_id"); notificationId != "" {
+		setNotificationPushStatus(app, notificationId, outboxStatusForNotification(app, notificationId))
+	}
+}
+
+// pushBackoff doubles the retry delay with every attempt, up to pushMaxBackoff.
+func pushBackoff(attempts int) time.Duration {
+	backoff := pushBaseBackoff
+	for i := 1; i < attempts && backoff < pushMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, pushMaxBackoff)
+}
+
+// outboxStatusForNotification summarizes the per-device outbox rows:
+// sent if any device received it, pending while retries remain, failed otherwise.
+func outboxStatusForNotification(app core.App, notificationId string) string {
+	var counts struct {
+		Sent    int `db:"sent"`
+		Pending int `db:"pending"`
+	}
+	_ = app.DB().NewQuery(`
+		SELECT
+			COALESCE(SUM(CASE WHEN status = 'sent' THEN 1 ELSE 0 END), 0) as sent,
+			COALESCE(SUM(CASE WHEN status = 'pending' THEN 1 ELSE 0 END), 0) as pending
+		FROM push_outbox
+		WHERE notification_id = {:id}
+	`).Bind(dbx.Params{"id": notificationId}).One(&counts)
+
+	switch {
+	case counts.Sent > 0:
+		return "sent"
+	case counts.Pending > 0:
+		return "pending"
+	default:
+		return "failed"
+	}
+}
+
+func setNotificationPushStatus(app core.App, notificationId, status string) {
+	_, _ = app.DB().NewQuery(
+		"UPDATE notifications SET push_status = {:status} WHERE id = {:id}",
+	).Bind(dbx.Params{"status": status, "id": notificationId}).Execute()
+}
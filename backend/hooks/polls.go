@@ -0,0 +1,89 @@
+package hooks
+
+import (
+	"log"
+	"strconv"
+
+	"minimo-backend/notify"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/dbx"
+)
+
+func RegisterPollJobs(app core.App) {
+	// 5분마다 마감 시간이 지난 투표 종료
+	app.Cron().MustAdd("close_expired_polls", "*/5 * * * *", func() {
+		closeExpiredPolls(app)
+	})
+}
+
+// closeExpiredPolls marks polls past their close time as closed
+// and notifies each post author with the results.
+func closeExpiredPolls(app core.App) {
+	polls, err := app.FindRecordsByFilter(
+		"polls",
+		"closed = false && closes_at != '' && closes_at <= @now",
+		"closes_at",
+		100,
+		0,
+	)
+	if err != nil {
+		log.Printf("[WARN] Failed to find expired polls: %v", err)
+		return
+	}
+
+	for _, poll := range polls {
+		poll.Set("closed", true)
+		if err := app.Save(poll); err != nil {
+			log.Printf("[WARN] Failed to close poll %s: %v", poll.Id, err)
+			continue
+		}
+
+		notifyPollResult(app, poll)
+	}
+
+	if len(polls) > 0 {
+		log.Printf("[INFO] Closed %d expired polls", len(polls))
+	}
+}
+
+func notifyPollResult(app core.App, poll *core.Record) {
+	postId := poll.GetString("post")
+	post, err := app.FindRecordById("community_posts", postId)
+	if err != nil {
+		return
+	}
+
+	authorId := post.GetString("owner")
+	if authorId == "" {
+		return
+	}
+
+	var top struct {
+		Label     string `db:"label"`
+		VoteCount int    `db:"vote_count"`
+	}
+	var total int
+	_ = app.DB().NewQuery(
+		"SELECT COALESCE(SUM(vote_count), 0) FROM poll_options WHERE poll = {:poll}",
+	).Bind(dbx.Params{"poll": poll.Id}).Row(&total)
+
+	event := notify.System("poll.closed_empty", authorId, postId, "post", nil)
+	if total > 0 {
+		err := app.DB().NewQuery(`
+			SELECT label, vote_count FROM poll_options
+			WHERE poll = {:poll}
+			ORDER BY vote_count DESC, position ASC
+			LIMIT 1
+		`).Bind(dbx.Params{"poll": poll.Id}).One(&top)
+		if err == nil {
+			event = notify.System("poll.closed", authorId, postId, "post", map[string]string{
+				"label": top.Label,
+				"votes": strconv.Itoa(top.VoteCount),
+				"total": strconv.Itoa(total),
+			})
+		}
+	}
+
+	notify.Send(app, event)
+}
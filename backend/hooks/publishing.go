@@ -0,0 +1,92 @@
+package hooks
+
+import (
+	"log"
+	"time"
+
+	"minimo-backend/notify"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func RegisterPublishingHooks(app core.App) {
+	app.OnRecordCreateRequest("community_posts", "questions").BindFunc(func(e *core.RecordRequestEvent) error {
+		if err := validateSchedule(e.Record); err != nil {
+			return err
+		}
+		return e.Next()
+	})
+
+	app.OnRecordUpdateRequest("community_posts", "questions").BindFunc(func(e *core.RecordRequestEvent) error {
+		if err := validateSchedule(e.Record); err != nil {
+			return err
+		}
+		return e.Next()
+	})
+
+	// 임시저장/예약 상태의 글에는 댓글과 답변을 달 수 없음
+	app.OnRecordCreateRequest("comments", "answers").BindFunc(func(e *core.RecordRequestEvent) error {
+		parentCollection, parentField := "community_posts", "post"
+		if e.Collection.Name == "answers" {
+			parentCollection, parentField = "questions", "question"
+		}
+
+		parent, err := e.App.FindRecordById(parentCollection, e.Record.GetString(parentField))
+		if err == nil {
+			if status := parent.GetString("status"); status == "draft" || status == "scheduled" {
+				return apis.NewBadRequestError("게시되지 않은 글에는 작성할 수 없습니다.", nil)
+			}
+		}
+		return e.Next()
+	})
+
+	// 매분 게시 예정 시간이 지난 게시글/질문 게시
+	app.Cron().MustAdd("publish_scheduled_content", "* * * * *", func() {
+		publishScheduled(app, "community_posts", "post")
+		publishScheduled(app, "questions", "question")
+	})
+}
+
+// validateSchedule requires scheduled records to carry a future publish time.
+func validateSchedule(record *core.Record) error {
+	if record.GetString("status") != "scheduled" {
+		return nil
+	}
+
+	publishAt := record.GetDateTime("publish_at")
+	if publishAt.IsZero() || !publishAt.Time().After(time.Now()) {
+		return apis.NewBadRequestError("예약 게시 시간은 현재 이후여야 합니다.", nil)
+	}
+	return nil
+}
+
+// publishScheduled switches due scheduled records to active. Saving the
+// status change fires the FTS update trigger, which indexes the record.
+func publishScheduled(app core.App, collectionName, targetType string) {
+	records, err := app.FindRecordsByFilter(
+		collectionName,
+		"status = 'scheduled' && publish_at != '' && publish_at <= @now",
+		"publish_at",
+		100,
+		0,
+	)
+	if err != nil {
+		log.Printf("[WARN] Failed to find scheduled %s: %v", collectionName, err)
+		return
+	}
+
+	for _, record := range records {
+		record.Set("status", "active")
+		if err := app.Save(record); err != nil {
+			log.Printf("[WARN] Failed to publish %s %s: %v", collectionName, record.Id, err)
+			continue
+		}
+
+		notify.Send(app, notify.System("publish."+targetType, record.GetString("owner"), record.Id, targetType, nil))
+	}
+
+	if len(records) > 0 {
+		log.Printf("[INFO] Published %d scheduled %s", len(records), collectionName)
+	}
+}
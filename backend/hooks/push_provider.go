@@ -0,0 +1,47 @@
+package hooks
+
+// pushMessage is a provider independent push payload.
+// Silent messages only update the app badge.
+type pushMessage struct {
+	Title  string
+	Body   string
+	Data   map[string]string
+	Badge  int
+	Silent bool
+}
+
+// pushTarget is one device push token and the platform it was registered for.
+type pushTarget struct {
+	Token    string `db:"token"`
+	Platform string `db:"platform"`
+}
+
+// pushProvider delivers push messages to the devices of one platform family.
+type pushProvider interface {
+	// Enabled reports whether the provider is configured on this server.
+	Enabled() bool
+	Send(token string, msg pushMessage) error
+}
+
+// providerFor returns the provider of a device platform.
+// Web browsers use Web Push, the mobile apps use FCM.
+func providerFor(platform string) pushProvider {
+	if platform == "web" {
+		return getWebPushProvider()
+	}
+	return fcmProvider{}
+}
+
+// fcmProvider sends through Firebase Cloud Messaging (iOS and Android).
+type fcmProvider struct{}
+
+func (fcmProvider) Enabled() bool {
+	return getFCMClient() != nil
+}
+
+func (fcmProvider) Send(token string, msg pushMessage) error {
+	if msg.Silent {
+		return sendSilentFCMPush(token, msg.Badge)
+	}
+	return sendDirectFCMPush(token, msg.Title, msg.Body, msg.Data, msg.Badge)
+}
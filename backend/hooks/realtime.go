@@ -0,0 +1,97 @@
+package hooks
+
+import (
+	"encoding/json"
+	"log"
+
+	"minimo-backend/notify"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/routine"
+	"github.com/pocketbase/pocketbase/tools/subscriptions"
+)
+
+// notificationStreamEvent is the payload sent on a user's notification topic.
+type notificationStreamEvent struct {
+	Action       string       `json:"action"`
+	UnreadCount  int          `json:"unread_count"`
+	Notification *core.Record `json:"notification,omitempty"`
+}
+
+// notificationsTopic is the realtime topic a client subscribes to
+// (POST /api/realtime) to receive its own notification stream.
+func notificationsTopic(userId string) string {
+	return "notifications/" + userId
+}
+
+// registerRealtimeHooks publishes the unread count and the changed notification
+// whenever a notification is created, updated (e.g. marked read) or deleted.
+func registerRealtimeHooks(app core.App) {
+	app.OnRecordAfterCreateSuccess("notifications").BindFunc(func(e *core.RecordEvent) error {
+		publishNotificationEvent(e.App, e.Record.GetString("user"), "create", e.Record)
+		return e.Next()
+	})
+
+	app.OnRecordAfterUpdateSuccess("notifications").BindFunc(func(e *core.RecordEvent) error {
+		publishNotificationEvent(e.App, e.Record.GetString("user"), "update", e.Record)
+		return e.Next()
+	})
+
+	app.OnRecordAfterDeleteSuccess("notifications").BindFunc(func(e *core.RecordEvent) error {
+		publishNotificationEvent(e.App, e.Record.GetString("user"), "delete", e.Record)
+		return e.Next()
+	})
+
+	// 일괄 읽음 처리 등 레코드 이벤트 없이 변경된 경우
+	notify.OnUnreadChanged(func(app core.App, userId string) {
+		publishNotificationEvent(app, userId, "read_all", nil)
+	})
+}
+
+// publishNotificationEvent sends the event to the realtime clients of the user
+// subscribed to its notification topic. Clients authenticated as a different
+// user never receive it, even if they subscribed to the topic.
+func publishNotificationEvent(app core.App, userId, action string, notification *core.Record) {
+	if userId == "" {
+		return
+	}
+
+	topic := notificationsTopic(userId)
+	var data []byte
+
+	for _, chunk := range app.SubscriptionsBroker().ChunkedClients(300) {
+		for _, client := range chunk {
+			subs := client.Subscriptions(topic)
+			if len(subs) == 0 {
+				continue
+			}
+
+			auth, _ := client.Get(apis.RealtimeClientAuthKey).(*core.Record)
+			if auth == nil || auth.Id != userId {
+				continue
+			}
+
+			// 구독자가 있을 때만 읽지 않은 알림 수를 계산
+			if data == nil {
+				var err error
+				data, err = json.Marshal(notificationStreamEvent{
+					Action:       action,
+					UnreadCount:  notify.UnreadCount(app, userId),
+					Notification: notification,
+				})
+				if err != nil {
+					log.Printf("[Notification] Failed to encode realtime event: %v", err)
+					return
+				}
+			}
+
+			for sub := range subs {
+				msg := subscriptions.Message{Name: sub, Data: data}
+				routine.FireAndForget(func() {
+					client.Send(msg)
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package hooks
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/pocketbase/dbx"
+)
+
+const retentionBatchSize = 500
+
+// retentionRule deletes the records of Collection matching Condition whose
+// AgeField is older than MaxAge. Records are deleted through the app, so
+// record hooks, file cleanup and relation cascades run as for any delete.
+type retentionRule struct {
+	Name       string
+	Collection string
+	Condition  string
+	AgeField   string
+	MaxAge     time.Duration
+}
+
+// 기본 보존 정책. RETENTION_<NAME>_DAYS 환경 변수로 기간을 조정할 수 있음
+// (예: RETENTION_READ_NOTIFICATIONS_DAYS=30, 0이면 규칙 비활성화)
+var defaultRetentionRules = []retentionRule{
+	{
+		Name:       "read_notifications",
+		Collection: "notifications",
+		Condition:  "is_read = 1",
+		AgeField:   "created",
+		MaxAge:     90 * 24 * time.Hour,
+	},
+	{
+		Name:       "used_verification_codes",
+		Collection: "verification_codes",
+		Condition:  "verified = 1",
+		AgeField:   "expires_at",
+		MaxAge:     24 * time.Hour,
+	},
+	// 만료된 코드는 인증 시도 시에도 삭제되며, 여기서 시도되지 않은 코드를 정리
+	{
+		Name:       "expired_verification_codes",
+		Collection: "verification_codes",
+		Condition:  "verified = 0",
+		AgeField:   "expires_at",
+		MaxAge:     0,
+	},
+	{
+		Name:       "sent_pushes",
+		Collection: "push_outbox",
+		Condition:  "status = 'sent'",
+		AgeField:   "created",
+		MaxAge:     7 * 24 * time.Hour,
+	},
+	{
+		Name:       "dead_pushes",
+		Collection: "push_outbox",
+		Condition:  "status = 'dead'",
+		AgeField:   "created",
+		MaxAge:     30 * 24 * time.Hour,
+	},
+}
+
+func RegisterRetentionJobs(app core.App) {
+	// 매 시간 보존 기간이 지난 데이터 정리
+	app.Cron().MustAdd("retention", "0 * * * *", func() {
+		runRetention(app, retentionRules())
+	})
+}
+
+// retentionRules returns the default rules with the environment overrides applied.
+func retentionRules() []retentionRule {
+	rules := make([]retentionRule, 0, len(defaultRetentionRules))
+	for _, rule := range defaultRetentionRules {
+		env := "RETENTION_" + strings.ToUpper(rule.Name) + "_DAYS"
+		if raw := os.Getenv(env); raw != "" {
+			days, err := strconv.Atoi(raw)
+			if err != nil || days < 0 {
+				log.Printf("[WARN] Invalid %s=%q, using default", env, raw)
+			} else if days == 0 && rule.MaxAge > 0 {
+				continue
+			} else {
+				rule.MaxAge = time.Duration(days) * 24 * time.Hour
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+func runRetention(app core.App, rules []retentionRule) {
+	for _, rule := range rules {
+		purged, err := applyRetentionRule(app, rule)
+		if err != nil {
+			log.Printf("[WARN] Retention rule %s failed after %d records: %v", rule.Name, purged, err)
+			continue
+		}
+		if purged > 0 {
+			log.Printf("[INFO] Retention rule %s purged %d records from %s", rule.Name, purged, rule.Collection)
+		}
+	}
+}
+
+// applyRetentionRule deletes matching records in batches until none are left.
+func applyRetentionRule(app core.App, rule retentionRule) (int64, error) {
+	collection, err := app.FindCollectionByNameOrId(rule.Collection)
+	if err != nil {
+		return 0, nil // 컬렉션이 없는 환경에서는 건너뜀
+	}
+
+	field := collection.Fields.GetByName(rule.AgeField)
+	if field == nil {
+		return 0, nil
+	}
+
+	// 날짜 필드는 PocketBase 형식, 텍스트로 저장된 날짜는 RFC3339로 비교
+	cutoff := time.Now().UTC().Add(-rule.MaxAge)
+	cutoffValue := cutoff.Format(time.RFC3339)
+	switch field.(type) {
+	case *core.DateField, *core.AutodateField:
+		dt, _ := types.ParseDateTime(cutoff)
+		cutoffValue = dt.String()
+	}
+
+	var total int64
+	for {
+		var records []*core.Record
+		err := app.RecordQuery(collection).
+			AndWhere(dbx.NewExp(rule.Condition)).
+			AndWhere(dbx.NewExp(rule.AgeField+" != ''")).
+			AndWhere(dbx.NewExp(rule.AgeField+" < {:cutoff}", dbx.Params{"cutoff": cutoffValue})).
+			Limit(retentionBatchSize).
+			All(&records)
+		if err != nil {
+			return total, err
+		}
+
+		err = app.RunInTransaction(func(txApp core.App) error {
+			for _, record := range records {
+				if err := txApp.Delete(record); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return total, err
+		}
+
+		total += int64(len(records))
+		if len(records) < retentionBatchSize {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package hooks
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// revisionTargets maps editable community collections to their revision target type.
+var revisionTargets = map[string]string{
+	"community_posts": "post",
+	"questions":       "question",
+	"comments":        "comment",
+	"answers":         "answer",
+}
+
+func RegisterRevisionHooks(app core.App) {
+	// edited_at은 서버에서만 설정 (클라이언트가 보낸 값은 무시)
+	app.OnRecordCreate("community_posts", "questions", "comments", "answers").BindFunc(func(e *core.RecordEvent) error {
+		e.Record.Set("edited_at", "")
+		return e.Next()
+	})
+
+	app.OnRecordUpdate("community_posts", "questions", "comments", "answers").BindFunc(func(e *core.RecordEvent) error {
+		original := e.Record.Original()
+		e.Record.Set("edited_at", original.Get("edited_at"))
+
+		// 제목/본문이 바뀐 경우만 수정으로 간주 (카운터 갱신 등은 제외)
+		edited := original.GetString("content") != e.Record.GetString("content") ||
+			original.GetString("title") != e.Record.GetString("title")
+		if !edited {
+			return e.Next()
+		}
+
+		// 임시저장/예약 상태에서의 수정은 이력을 남기지 않음
+		status := original.GetString("status")
+		if status == "draft" || status == "scheduled" {
+			return e.Next()
+		}
+
+		e.Record.Set("edited_at", types.NowDateTime())
+
+		// 수정 저장과 이전 버전 기록을 하나의 트랜잭션으로 처리
+		originalApp := e.App
+		err := e.App.RunInTransaction(func(txApp core.App) error {
+			e.App = txApp
+
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			return saveRevision(txApp, e.Record.Collection().Name, original)
+		})
+		e.App = originalApp
+
+		return err
+	})
+}
+
+// saveRevision stores the content of a record as it was before an edit.
+func saveRevision(app core.App, collectionName string, original *core.Record) error {
+	collection, err := app.FindCollectionByNameOrId("content_revisions")
+	if err != nil {
+		return err
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("target_id", original.Id)
+	record.Set("target_type", revisionTargets[collectionName])
+	record.Set("title", original.GetString("title"))
+	record.Set("content", original.GetString("content"))
+
+	// 이전 버전이 작성된 시각: 최초 작성본이면 created, 이후 수정본이면 edited_at
+	writtenAt := original.GetDateTime("edited_at")
+	if writtenAt.IsZero() {
+		writtenAt = original.GetDateTime("created")
+	}
+	record.Set("written_at", writtenAt)
+
+	return app.Save(record)
+}
@@ -1,175 +1,143 @@
 package hooks
 
 import (
-	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
-	"math/rand"
-	"net/http"
+	"math/big"
 	"os"
 	"sync"
 	"time"
 
+	"minimo-backend/mailer"
+	"minimo-backend/middleware"
+	"minimo-backend/notify"
+
 	"github.com/pocketbase/pocketbase/apis"
 	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/dbx"
 )
 
-// rateLimiter는 이메일별 인증 코드 요청 횟수를 제한합니다.
-type rateLimiter struct {
-	mu       sync.Mutex
-	attempts map[string][]time.Time
-}
-
-var limiter = &rateLimiter{
-	attempts: make(map[string][]time.Time),
-}
-
 const (
-	maxAttemptsPerWindow = 5               // 윈도우 내 최대 요청 횟수
-	rateLimitWindow      = 10 * time.Minute // Rate limit 윈도우
-	maxVerifyAttempts    = 5                // 인증 시도 최대 횟수
-	verifyWindow         = 3 * time.Minute  // 인증 시도 윈도우
+	codeDigits      = 6
+	codeTTL         = 3 * time.Minute
+	maxCodeAttempts = 5 // 코드 하나당 허용되는 오답 횟수, 초과하면 코드 무효화
 )
 
-// isRateLimited checks if the given key has exceeded the rate limit.
-func (r *rateLimiter) isRateLimited(key string, maxAttempts int, window time.Duration) bool {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	now := time.Now()
-	cutoff := now.Add(-window)
-
-	// 윈도우 밖의 오래된 기록 제거
-	var valid []time.Time
-	for _, t := range r.attempts[key] {
-		if t.After(cutoff) {
-			valid = append(valid, t)
-		}
-	}
-	r.attempts[key] = valid
-
-	return len(valid) >= maxAttempts
-}
-
-// record adds a new attempt for the given key.
-func (r *rateLimiter) record(key string) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	r.attempts[key] = append(r.attempts[key], time.Now())
-}
-
-func RegisterVerificationRoutes(app core.App) {
+func RegisterVerificationRoutes(app core.App, rateLimits middleware.RateLimitStore) {
 	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
-		se.Router.POST("/api/custom/send-code", handleSendCode(app))
-		se.Router.POST("/api/custom/verify-code", handleVerifyCode(app))
+		// 코드 발송: 이메일당 10분에 5회, IP당 1시간에 20회
+		se.Router.POST("/api/custom/send-code", handleSendCode(app)).
+			BindFunc(middleware.RateLimit(rateLimits, middleware.RateLimitRule{
+				Name:    "send_code",
+				Limit:   5,
+				Window:  10 * time.Minute,
+				Key:     middleware.ByBodyField("email"),
+				Message: "너무 많은 요청입니다. 잠시 후 다시 시도해주세요.",
+			})).
+			BindFunc(middleware.RateLimit(rateLimits, middleware.RateLimitRule{
+				Name:    "send_code_ip",
+				Limit:   20,
+				Window:  time.Hour,
+				Key:     middleware.ByIP,
+				Message: "너무 많은 요청입니다. 잠시 후 다시 시도해주세요.",
+			}))
+
+		// 코드 확인: 이메일당 3분에 5회, IP당 10분에 30회
+		se.Router.POST("/api/custom/verify-code", handleVerifyCode(app)).
+			BindFunc(middleware.RateLimit(rateLimits, middleware.RateLimitRule{
+				Name:    "verify_code",
+				Limit:   5,
+				Window:  3 * time.Minute,
+				Key:     middleware.ByBodyField("email"),
+				Message: "인증 시도 횟수를 초과했습니다. 잠시 후 다시 시도해주세요.",
+			})).
+			BindFunc(middleware.RateLimit(rateLimits, middleware.RateLimitRule{
+				Name:    "verify_code_ip",
+				Limit:   30,
+				Window:  10 * time.Minute,
+				Key:     middleware.ByIP,
+				Message: "인증 시도 횟수를 초과했습니다. 잠시 후 다시 시도해주세요.",
+			}))
 		return se.Next()
 	})
-
-	// 매 시간마다 만료된 인증 코드 정리
-	app.Cron().MustAdd("cleanup_expired_codes", "0 * * * *", func() {
-		cleanupExpiredCodes(app)
-	})
-}
-
-// cleanupExpiredCodes removes expired or used verification codes.
-func cleanupExpiredCodes(app core.App) {
-	records, err := app.FindRecordsByFilter(
-		"verification_codes",
-		"verified = true || expires_at < {:now}",
-		"-created",
-		500,
-		0,
-		map[string]any{"now": time.Now().UTC().Format(time.RFC3339)},
-	)
-	if err != nil {
-		log.Printf("[WARN] Failed to find expired codes: %v", err)
-		return
-	}
-
-	for _, record := range records {
-		if err := app.Delete(record); err != nil {
-			log.Printf("[WARN] Failed to delete expired code %s: %v", record.Id, err)
-		}
-	}
-
-	if len(records) > 0 {
-		log.Printf("[INFO] Cleaned up %d expired/used verification codes", len(records))
-	}
 }
 
 func handleSendCode(app core.App) func(e *core.RequestEvent) error {
 	return func(e *core.RequestEvent) error {
 		var body struct {
-			Email string `json:"email"`
+			Email  string `json:"email"`
+			Locale string `json:"locale"`
 		}
 		if err := json.NewDecoder(e.Request.Body).Decode(&body); err != nil || body.Email == "" {
 			return apis.NewBadRequestError("이메일이 필요합니다.", nil)
 		}
 
-		// Rate limiting: 이메일당 10분에 5회까지
-		rateLimitKey := "send:" + body.Email
-		if limiter.isRateLimited(rateLimitKey, maxAttemptsPerWindow, rateLimitWindow) {
-			return apis.NewTooManyRequestsError("너무 많은 요청입니다. 잠시 후 다시 시도해주세요.", nil)
+		if mailer.Default() == nil {
+			return e.JSON(500, map[string]string{"error": "메일 발송 설정이 완료되지 않았습니다."})
 		}
-		limiter.record(rateLimitKey)
 
-		code := fmt.Sprintf("%04d", rand.Intn(9000)+1000)
-		expiresAt := time.Now().Add(3 * time.Minute).UTC().Format(time.RFC3339)
-
-		collection, err := app.FindCollectionByNameOrId("verification_codes")
+		code, err := generateCode()
 		if err != nil {
 			return e.JSON(500, map[string]string{"error": "서버 오류가 발생했습니다."})
 		}
-
-		record := core.NewRecord(collection)
-		record.Set("email", body.Email)
-		record.Set("code", code)
-		record.Set("expires_at", expiresAt)
-		record.Set("verified", false)
-
-		if err := app.Save(record); err != nil {
+		codeHash, err := hashCode(code)
+		if err != nil {
 			return e.JSON(500, map[string]string{"error": "서버 오류가 발생했습니다."})
 		}
+		expiresAt := time.Now().Add(codeTTL).UTC().Format(time.RFC3339)
 
-		apiKey := os.Getenv("RESEND_API_KEY")
-		if apiKey == "" {
-			return e.JSON(500, map[string]string{"error": "메일 발송 설정이 완료되지 않았습니다."})
+		collection, err := app.FindCollectionByNameOrId("verification_codes")
+		if err != nil {
+			return e.JSON(500, map[string]string{"error": "서버 오류가 발생했습니다."})
 		}
 
-		fromEmail := os.Getenv("RESEND_FROM_EMAIL")
-		if fromEmail == "" {
-			fromEmail = "우물 <onboarding@resend.dev>"
+		// 새 코드를 발급하면 같은 이메일의 사용하지 않은 이전 코드는 모두 무효화
+		err = app.RunInTransaction(func(txApp core.App) error {
+			_, err := txApp.DB().NewQuery(
+				"DELETE FROM verification_codes WHERE email = {:email} AND verified = 0",
+			).Bind(dbx.Params{"email": body.Email}).Execute()
+			if err != nil {
+				return err
+			}
+
+			record := core.NewRecord(collection)
+			record.Set("email", body.Email)
+			record.Set("code_hash", codeHash)
+			record.Set("attempts", 0)
+			record.Set("expires_at", expiresAt)
+			record.Set("verified", false)
+			return txApp.Save(record)
+		})
+		if err != nil {
+			return e.JSON(500, map[string]string{"error": "서버 오류가 발생했습니다."})
 		}
 
-		htmlContent := `<div style="font-family: Arial, sans-serif; max-width: 600px; margin: 0 auto; padding: 40px 20px;">` +
-			`<h1 style="color: #0165FE; font-size: 24px; margin-bottom: 30px;">우물 이메일 인증</h1>` +
-			`<p style="font-size: 16px; color: #333; margin-bottom: 20px;">안녕하세요! 우물 회원가입을 위한 인증번호입니다.</p>` +
-			`<div style="background: #F5F7FA; border-radius: 12px; padding: 30px; text-align: center; margin: 30px 0;">` +
-			`<p style="font-size: 14px; color: #666; margin-bottom: 10px;">인증번호</p>` +
-			`<p style="font-size: 36px; font-weight: bold; color: #0165FE; letter-spacing: 8px; margin: 0;">` + code + `</p>` +
-			`</div>` +
-			`<p style="font-size: 14px; color: #999;">이 인증번호는 3분간 유효합니다.<br>본인이 요청하지 않았다면 이 이메일을 무시해주세요.</p>` +
-			`</div>`
-
-		emailPayload, _ := json.Marshal(map[string]any{
-			"from":    fromEmail,
-			"to":      []string{body.Email},
-			"subject": "[우물] 이메일 인증번호",
-			"html":    htmlContent,
+		// 가입 전이므로 요청의 locale 또는 Accept-Language로 언어 결정
+		locale := body.Locale
+		if locale == "" {
+			locale = e.Request.Header.Get("Accept-Language")
+		}
+		subject, html, err := mailer.Render(notify.NormalizeLocale(locale), "verification", map[string]any{
+			"Code":           code,
+			"ExpiresMinutes": int(codeTTL.Minutes()),
 		})
-
-		req, _ := http.NewRequest("POST", "https://api.resend.com/emails", bytes.NewReader(emailPayload))
-		req.Header.Set("Authorization", "Bearer "+apiKey)
-		req.Header.Set("Content-Type", "application/json")
-
-		resp, err := http.DefaultClient.Do(req)
 		if err != nil {
-			return e.JSON(500, map[string]string{"error": "메일 발송에 실패했습니다."})
+			log.Printf("[Mail] Failed to render verification email: %v", err)
+			return e.JSON(500, map[string]string{"error": "서버 오류가 발생했습니다."})
 		}
-		defer resp.Body.Close()
 
-		if resp.StatusCode != 200 {
+		// 발송 실패 시 제공자 응답은 mailer에서 로그로 남김
+		err = mailer.Send(&mailer.Message{
+			To:      []string{body.Email},
+			Subject: subject,
+			HTML:    html,
+		})
+		if err != nil {
 			return e.JSON(500, map[string]string{"error": "메일 발송에 실패했습니다."})
 		}
 
@@ -190,20 +158,15 @@ func handleVerifyCode(app core.App) func(e *core.RequestEvent) error {
 			return apis.NewBadRequestError("이메일과 인증 코드가 필요합니다.", nil)
 		}
 
-		// Rate limiting: 인증 시도는 이메일당 3분에 5회까지
-		verifyKey := "verify:" + body.Email
-		if limiter.isRateLimited(verifyKey, maxVerifyAttempts, verifyWindow) {
-			return apis.NewTooManyRequestsError("인증 시도 횟수를 초과했습니다. 잠시 후 다시 시도해주세요.", nil)
-		}
-		limiter.record(verifyKey)
-
-		record, err := app.FindFirstRecordByFilter("verification_codes",
-			"email = {:email} && code = {:code} && verified = false",
-			map[string]any{"email": body.Email, "code": body.Code},
+		records, err := app.FindRecordsByFilter("verification_codes",
+			"email = {:email} && verified = false",
+			"-expires_at", 1, 0,
+			dbx.Params{"email": body.Email},
 		)
-		if err != nil || record == nil {
+		if err != nil || len(records) == 0 {
 			return apis.NewBadRequestError("유효하지 않은 인증 코드입니다.", nil)
 		}
+		record := records[0]
 
 		expiresAt, err := time.Parse(time.RFC3339, record.GetString("expires_at"))
 		if err != nil || time.Now().After(expiresAt) {
@@ -211,6 +174,30 @@ func handleVerifyCode(app core.App) func(e *core.RequestEvent) error {
 			return apis.NewBadRequestError("인증 코드가 만료되었습니다.", nil)
 		}
 
+		// 비교 전에 시도 횟수를 먼저 차감 (동시 요청으로 제한을 넘지 않도록)
+		result, err := app.DB().NewQuery(
+			"UPDATE verification_codes SET attempts = attempts + 1 WHERE id = {:id} AND attempts < {:max}",
+		).Bind(dbx.Params{"id": record.Id, "max": maxCodeAttempts}).Execute()
+		if err != nil {
+			return e.JSON(500, map[string]string{"error": "서버 오류가 발생했습니다."})
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			_ = app.Delete(record)
+			return apis.NewBadRequestError("인증 시도 횟수를 초과했습니다. 인증 코드를 다시 요청해주세요.", nil)
+		}
+
+		if !checkCode(record.GetString("code_hash"), body.Code) {
+			if record.GetInt("attempts")+1 >= maxCodeAttempts {
+				_ = app.Delete(record)
+				return apis.NewBadRequestError("인증 시도 횟수를 초과했습니다. 인증 코드를 다시 요청해주세요.", nil)
+			}
+			return apis.NewBadRequestError("유효하지 않은 인증 코드입니다.", nil)
+		}
+
+		record, err = app.FindRecordById("verification_codes", record.Id)
+		if err != nil {
+			return e.JSON(500, map[string]string{"error": "서버 오류가 발생했습니다."})
+		}
 		record.Set("verified", true)
 		if err := app.Save(record); err != nil {
 			return e.JSON(500, map[string]string{"error": "서버 오류가 발생했습니다."})
@@ -222,3 +209,63 @@ func handleVerifyCode(app core.App) func(e *core.RequestEvent) error {
 		})
 	}
 }
+
+// generateCode returns a uniformly random numeric code of codeDigits digits.
+func generateCode() (string, error) {
+	limit := big.NewInt(1)
+	for i := 0; i < codeDigits; i++ {
+		limit.Mul(limit, big.NewInt(10))
+	}
+
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", codeDigits, n), nil
+}
+
+// codeSecret is the HMAC key of verification codes. It is never stored in the
+// database, so a leaked verification_codes table does not reveal the codes.
+var (
+	codeSecret     []byte
+	codeSecretErr  error
+	codeSecretOnce sync.Once
+)
+
+// verificationSecret returns VERIFICATION_CODE_SECRET, or a random key when it
+// is not set. A random key only lives as long as the process, so codes sent
+// before a restart or by another instance no longer match.
+func verificationSecret() ([]byte, error) {
+	codeSecretOnce.Do(func() {
+		if secret := os.Getenv("VERIFICATION_CODE_SECRET"); secret != "" {
+			codeSecret = []byte(secret)
+			return
+		}
+
+		log.Println("[Verification] VERIFICATION_CODE_SECRET not set, using a per-process key")
+		codeSecret = make([]byte, 32)
+		_, codeSecretErr = rand.Read(codeSecret)
+	})
+	return codeSecret, codeSecretErr
+}
+
+// hashCode returns the hex HMAC-SHA256 of the code under verificationSecret.
+func hashCode(code string) (string, error) {
+	secret, err := verificationSecret()
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(code))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// checkCode reports whether code matches a hash created by hashCode.
+func checkCode(stored, code string) bool {
+	codeHash, err := hashCode(code)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal([]byte(codeHash), []byte(stored))
+}
@@ -0,0 +1,317 @@
+package hooks
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"minimo-backend/webpush"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	webPushTTL        = 24 * time.Hour
+	webPushRecordSize = 4096
+)
+
+// errStaleSubscription is returned when the push service reports that the
+// browser subscription no longer exists (HTTP 404/410).
+var errStaleSubscription = errors.New("web push subscription is no longer valid")
+
+var (
+	webPushProviderInstance *webPushProvider
+	webPushProviderOnce     sync.Once
+)
+
+// webPushProvider sends standard Web Push messages (RFC 8030) with VAPID
+// authentication (RFC 8292) and aes128gcm encrypted payloads (RFC 8291).
+type webPushProvider struct {
+	privateKey *ecdsa.PrivateKey
+	publicKey  []byte // uncompressed P-256 point
+	subject    string
+	client     *http.Client
+}
+
+// getWebPushProvider returns the provider configured by VAPID_PRIVATE_KEY
+// (base64url raw P-256 scalar) and VAPID_SUBJECT, or nil when not configured.
+func getWebPushProvider() *webPushProvider {
+	webPushProviderOnce.Do(func() {
+		privateKey := os.Getenv("VAPID_PRIVATE_KEY")
+		if privateKey == "" {
+			log.Println("[WebPush] VAPID_PRIVATE_KEY not set, web push disabled")
+			return
+		}
+
+		subject := os.Getenv("VAPID_SUBJECT")
+		if subject == "" {
+			subject = "mailto:admin@example.com"
+		}
+
+		provider, err := newWebPushProvider(privateKey, subject, webpush.NewClient(15*time.Second))
+		if err != nil {
+			log.Printf("[WebPush] Invalid VAPID key: %v", err)
+			return
+		}
+
+		webPushProviderInstance = provider
+		log.Println("[WebPush] VAPID provider initialized")
+	})
+
+	return webPushProviderInstance
+}
+
+func newWebPushProvider(privateKeyB64, subject string, client *http.Client) (*webPushProvider, error) {
+	d, err := decodeBase64URL(privateKeyB64)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ecdh.P256().NewPrivateKey(d)
+	if err != nil {
+		return nil, err
+	}
+	publicKey := key.PublicKey().Bytes()
+
+	return &webPushProvider{
+		privateKey: &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     new(big.Int).SetBytes(publicKey[1:33]),
+				Y:     new(big.Int).SetBytes(publicKey[33:]),
+			},
+			D: new(big.Int).SetBytes(d),
+		},
+		publicKey: publicKey,
+		subject:   subject,
+		client:    client,
+	}, nil
+}
+
+func (p *webPushProvider) Enabled() bool {
+	return p != nil
+}
+
+// PublicKey returns the application server key browsers subscribe with.
+func (p *webPushProvider) PublicKey() string {
+	return base64.RawURLEncoding.EncodeToString(p.publicKey)
+}
+
+func (p *webPushProvider) Send(token string, msg pushMessage) error {
+	if p == nil {
+		return nil // push disabled
+	}
+
+	var sub webpush.Subscription
+	if err := json.Unmarshal([]byte(token), &sub); err != nil || sub.Endpoint == "" {
+		return errStaleSubscription
+	}
+
+	payload, err := json.Marshal(map[string]any{
+		"title":  msg.Title,
+		"body":   msg.Body,
+		"data":   msg.Data,
+		"badge":  msg.Badge,
+		"silent": msg.Silent,
+	})
+	if err != nil {
+		return err
+	}
+
+	body, err := encryptWebPushPayload(sub, payload)
+	if err != nil {
+		return err
+	}
+
+	authorization, err := p.vapidAuthorization(sub.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.Itoa(int(webPushTTL.Seconds())))
+	if msg.Silent {
+		req.Header.Set("Urgency", "low")
+	} else {
+		req.Header.Set("Urgency", "high")
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return errStaleSubscription
+	case resp.StatusCode >= 300:
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("web push failed with status %d: %s", resp.StatusCode, respBody)
+	}
+
+	return nil
+}
+
+// vapidAuthorization builds the "vapid t=<jwt>, k=<key>" header for the
+// push service origin of the endpoint.
+func (p *webPushProvider) vapidAuthorization(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"typ":"JWT","alg":"ES256"}`))
+	claims, err := json.Marshal(map[string]any{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": time.Now().Add(12 * time.Hour).Unix(),
+		"sub": p.subject,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+
+	r, s, err := ecdsa.Sign(rand.Reader, p.privateKey, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	// ES256 서명은 r, s를 각각 32바이트로 이어붙인 형식
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+
+	jwt := unsigned + "." + base64.RawURLEncoding.EncodeToString(signature)
+	return "vapid t=" + jwt + ", k=" + p.PublicKey(), nil
+}
+
+// encryptWebPushPayload encrypts the payload for the subscription with the
+// aes128gcm content encoding of RFC 8291, as a single record.
+func encryptWebPushPayload(sub webpush.Subscription, plaintext []byte) ([]byte, error) {
+	uaPublicBytes, err := decodeBase64URL(sub.Keys.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	authSecret, err := decodeBase64URL(sub.Keys.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid auth secret: %w", err)
+	}
+
+	uaPublic, err := ecdh.P256().NewPublicKey(uaPublicBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+
+	// 메시지마다 새로운 임시 키와 salt 사용
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+
+	sharedSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	keyInfo := append([]byte("WebPush: info\x00"), uaPublicBytes...)
+	keyInfo = append(keyInfo, asPublic...)
+	ikm, err := hkdfExpand(sharedSecret, authSecret, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	cek, err := hkdfExpand(ikm, salt, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdfExpand(ikm, salt, []byte("Content-Encoding: nonce\x00"), 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// 0x02: 마지막 레코드 구분자
+	record := append(append([]byte{}, plaintext...), 0x02)
+	if len(record)+gcm.Overhead() > webPushRecordSize {
+		return nil, errors.New("web push payload too large")
+	}
+	ciphertext := gcm.Seal(nil, nonce, record, nil)
+
+	// 헤더: salt(16) | record size(4) | key id length(1) | key id(서버 임시 공개키)
+	header := make([]byte, 0, 21+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, webPushRecordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	return append(header, ciphertext...), nil
+}
+
+func hkdfExpand(secret, salt, info []byte, length int) ([]byte, error) {
+	out := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, info), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// decodeBase64URL accepts base64url with or without padding, as browsers
+// and key generators differ.
+func decodeBase64URL(value string) ([]byte, error) {
+	if b, err := base64.RawURLEncoding.DecodeString(value); err == nil {
+		return b, nil
+	}
+	return base64.URLEncoding.DecodeString(value)
+}
+
+// WebPushPublicKey returns the VAPID public key web clients subscribe with,
+// or "" when web push is not configured.
+func WebPushPublicKey() string {
+	provider := getWebPushProvider()
+	if !provider.Enabled() {
+		return ""
+	}
+	return provider.PublicKey()
+}
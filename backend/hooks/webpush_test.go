@@ -0,0 +1,353 @@
+package hooks
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"minimo-backend/webpush"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// receivedPush is what the fake push service saw of one request.
+type receivedPush struct {
+	header http.Header
+	body   []byte
+}
+
+func newTestWebPushProvider(t *testing.T) *webPushProvider {
+	t.Helper()
+
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider, err := newWebPushProvider(
+		base64.RawURLEncoding.EncodeToString(key.Bytes()),
+		"mailto:test@example.com",
+		http.DefaultClient,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return provider
+}
+
+// newTestSubscription returns a browser subscription for endpoint with its
+// private key and auth secret, as a user agent would create them.
+func newTestSubscription(t *testing.T, endpoint string) (webpush.Subscription, *ecdh.PrivateKey, []byte) {
+	t.Helper()
+
+	uaKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authSecret := make([]byte, 16)
+	if _, err := rand.Read(authSecret); err != nil {
+		t.Fatal(err)
+	}
+
+	var sub webpush.Subscription
+	sub.Endpoint = endpoint
+	sub.Keys.P256dh = base64.RawURLEncoding.EncodeToString(uaKey.PublicKey().Bytes())
+	sub.Keys.Auth = base64.RawURLEncoding.EncodeToString(authSecret)
+	return sub, uaKey, authSecret
+}
+
+func TestWebPushSendRoundTrip(t *testing.T) {
+	received := make(chan receivedPush, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- receivedPush{header: r.Header.Clone(), body: body}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	provider := newTestWebPushProvider(t)
+	sub, uaKey, authSecret := newTestSubscription(t, server.URL+"/push/abc")
+	token, _ := json.Marshal(sub)
+
+	err := provider.Send(string(token), pushMessage{
+		Title: "새 댓글",
+		Body:  "누군가 댓글을 남겼습니다",
+		Data:  map[string]string{"type": "comment", "target_id": "post1"},
+		Badge: 3,
+	})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	push := <-received
+
+	if got := push.header.Get("Content-Encoding"); got != "aes128gcm" {
+		t.Errorf("Content-Encoding = %q, want aes128gcm", got)
+	}
+	if got := push.header.Get("Urgency"); got != "high" {
+		t.Errorf("Urgency = %q, want high", got)
+	}
+	if push.header.Get("TTL") == "" {
+		t.Error("TTL header is missing")
+	}
+
+	checkVAPIDAuthorization(t, push.header.Get("Authorization"), provider, server.URL)
+
+	plaintext := decryptWebPushBody(t, push.body, uaKey, authSecret)
+
+	var payload struct {
+		Title  string            `json:"title"`
+		Body   string            `json:"body"`
+		Data   map[string]string `json:"data"`
+		Badge  int               `json:"badge"`
+		Silent bool              `json:"silent"`
+	}
+	if err := json.Unmarshal(plaintext, &payload); err != nil {
+		t.Fatalf("decrypted payload is not JSON: %v (%q)", err, plaintext)
+	}
+	if payload.Title != "새 댓글" || payload.Body != "누군가 댓글을 남겼습니다" {
+		t.Errorf("unexpected title/body: %+v", payload)
+	}
+	if payload.Data["target_id"] != "post1" || payload.Badge != 3 || payload.Silent {
+		t.Errorf("unexpected data/badge/silent: %+v", payload)
+	}
+}
+
+func TestWebPushSendGoneIsStale(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer server.Close()
+
+	provider := newTestWebPushProvider(t)
+	sub, _, _ := newTestSubscription(t, server.URL+"/push/gone")
+	token, _ := json.Marshal(sub)
+
+	err := provider.Send(string(token), pushMessage{Silent: true})
+	if !errors.Is(err, errStaleSubscription) {
+		t.Fatalf("Send error = %v, want errStaleSubscription", err)
+	}
+	if !isStaleTokenError(err) {
+		t.Fatal("isStaleTokenError should report a gone subscription as stale")
+	}
+}
+
+func TestWebPushClientRejectsPrivateAddress(t *testing.T) {
+	hit := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hit = true
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	t.Setenv("WEBPUSH_ALLOW_PRIVATE_ENDPOINTS", "")
+	provider := newTestWebPushProvider(t)
+	provider.client = webpush.NewClient(5 * time.Second)
+	sub, _, _ := newTestSubscription(t, server.URL+"/push/internal")
+	token, _ := json.Marshal(sub)
+
+	err := provider.Send(string(token), pushMessage{Silent: true})
+	if !errors.Is(err, webpush.ErrPrivateAddress) {
+		t.Fatalf("Send error = %v, want ErrPrivateAddress", err)
+	}
+	if hit {
+		t.Fatal("the push client connected to a loopback address")
+	}
+}
+
+func TestWebPushClientDoesNotFollowRedirects(t *testing.T) {
+	redirected := false
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected = true
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer internal.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, internal.URL, http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	// 테스트 서버가 루프백에 있으므로 사설 주소를 허용하고 리다이렉트만 검사
+	t.Setenv("WEBPUSH_ALLOW_PRIVATE_ENDPOINTS", "true")
+	provider := newTestWebPushProvider(t)
+	provider.client = webpush.NewClient(5 * time.Second)
+	sub, _, _ := newTestSubscription(t, server.URL+"/push/redirect")
+	token, _ := json.Marshal(sub)
+
+	if err := provider.Send(string(token), pushMessage{Silent: true}); err == nil {
+		t.Fatal("Send should fail on a redirect response")
+	}
+	if redirected {
+		t.Fatal("the push client followed a redirect")
+	}
+}
+
+// checkVAPIDAuthorization verifies the "vapid t=<jwt>, k=<key>" header: the
+// key is the provider's public key and the JWT is a valid ES256 token for the
+// push service origin.
+func checkVAPIDAuthorization(t *testing.T, authorization string, provider *webPushProvider, origin string) {
+	t.Helper()
+
+	rest, ok := strings.CutPrefix(authorization, "vapid ")
+	if !ok {
+		t.Fatalf("Authorization %q is not a vapid header", authorization)
+	}
+	params := map[string]string{}
+	for _, part := range strings.Split(rest, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		params[k] = v
+	}
+
+	if params["k"] != provider.PublicKey() {
+		t.Errorf("k = %q, want the provider public key %q", params["k"], provider.PublicKey())
+	}
+
+	keyBytes, err := base64.RawURLEncoding.DecodeString(params["k"])
+	if err != nil || len(keyBytes) != 65 {
+		t.Fatalf("invalid k: %v", err)
+	}
+	publicKey := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(keyBytes[1:33]),
+		Y:     new(big.Int).SetBytes(keyBytes[33:]),
+	}
+
+	parts := strings.Split(params["t"], ".")
+	if len(parts) != 3 {
+		t.Fatalf("JWT %q does not have 3 parts", params["t"])
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Typ string `json:"typ"`
+	}
+	decodeJWTPart(t, parts[0], &header)
+	if header.Alg != "ES256" || header.Typ != "JWT" {
+		t.Errorf("unexpected JWT header: %+v", header)
+	}
+
+	var claims struct {
+		Aud string `json:"aud"`
+		Exp int64  `json:"exp"`
+		Sub string `json:"sub"`
+	}
+	decodeJWTPart(t, parts[1], &claims)
+	if claims.Aud != origin {
+		t.Errorf("aud = %q, want %q", claims.Aud, origin)
+	}
+	if claims.Sub != "mailto:test@example.com" {
+		t.Errorf("sub = %q", claims.Sub)
+	}
+	exp := time.Unix(claims.Exp, 0)
+	if !exp.After(time.Now()) || exp.After(time.Now().Add(24*time.Hour)) {
+		t.Errorf("exp %v must be in the future and at most 24h away", exp)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || len(signature) != 64 {
+		t.Fatalf("invalid ES256 signature: %v", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:])
+	if !ecdsa.Verify(publicKey, digest[:], r, s) {
+		t.Error("JWT signature does not verify with k")
+	}
+}
+
+func decodeJWTPart(t *testing.T, part string, v any) {
+	t.Helper()
+
+	raw, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		t.Fatalf("invalid JWT part %q: %v", part, err)
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		t.Fatalf("invalid JWT JSON %q: %v", raw, err)
+	}
+}
+
+// decryptWebPushBody decrypts an aes128gcm body (RFC 8291) with the user
+// agent's private key and auth secret, as the browser does.
+func decryptWebPushBody(t *testing.T, body []byte, uaKey *ecdh.PrivateKey, authSecret []byte) []byte {
+	t.Helper()
+
+	if len(body) < 21 {
+		t.Fatalf("body too short: %d bytes", len(body))
+	}
+	salt := body[:16]
+	recordSize := binary.BigEndian.Uint32(body[16:20])
+	idLen := int(body[20])
+	if len(body) < 21+idLen {
+		t.Fatalf("body too short for key id of %d bytes", idLen)
+	}
+	asPublicBytes := body[21 : 21+idLen]
+	ciphertext := body[21+idLen:]
+
+	if recordSize != webPushRecordSize {
+		t.Errorf("record size = %d, want %d", recordSize, webPushRecordSize)
+	}
+
+	asPublic, err := ecdh.P256().NewPublicKey(asPublicBytes)
+	if err != nil {
+		t.Fatalf("invalid server public key: %v", err)
+	}
+	shared, err := uaKey.ECDH(asPublic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyInfo := append([]byte("WebPush: info\x00"), uaKey.PublicKey().Bytes()...)
+	keyInfo = append(keyInfo, asPublicBytes...)
+	ikm := readHKDF(t, shared, authSecret, keyInfo, 32)
+	cek := readHKDF(t, ikm, salt, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce := readHKDF(t, ikm, salt, []byte("Content-Encoding: nonce\x00"), 12)
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		t.Fatalf("failed to decrypt push body: %v", err)
+	}
+
+	// 마지막 레코드는 0x02 구분자 뒤에 0x00 패딩이 올 수 있음
+	end := len(record) - 1
+	for end >= 0 && record[end] == 0x00 {
+		end--
+	}
+	if end < 0 || record[end] != 0x02 {
+		t.Fatalf("last record delimiter missing: %x", record)
+	}
+	return record[:end]
+}
+
+func readHKDF(t *testing.T, secret, salt, info []byte, length int) []byte {
+	t.Helper()
+
+	out := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, info), out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
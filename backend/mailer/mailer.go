@@ -0,0 +1,126 @@
+// Package mailer sends transactional email. Callers build a Message (usually
+// from one of the embedded templates) and hand it to the configured Mailer,
+// so the delivery provider can change without touching the callers.
+package mailer
+
+import (
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// ErrNotConfigured is returned when no email provider is configured.
+var ErrNotConfigured = errors.New("mailer: no email provider configured")
+
+// Message is a single email. From may be empty to use the provider's sender.
+type Message struct {
+	From    string
+	To      []string
+	Subject string
+	HTML    string
+	Headers map[string]string
+}
+
+// Mailer delivers email messages.
+type Mailer interface {
+	// Name identifies the provider in logs.
+	Name() string
+	Send(msg *Message) error
+}
+
+// availability is implemented by mailers whose configuration can change while
+// the server runs, such as SMTP reading the PocketBase settings.
+type availability interface {
+	Available() bool
+}
+
+var (
+	defaultMu     sync.RWMutex
+	defaultMailer Mailer
+)
+
+// Setup selects the default mailer. MAILER picks a provider explicitly
+// ("resend", "smtp" or "file"); when unset Resend is used if RESEND_API_KEY
+// is set, and the PocketBase SMTP settings otherwise. SMTP is checked on every
+// send, so enabling it in the admin settings needs no restart.
+// Must be called after the app is bootstrapped, e.g. in OnServe.
+func Setup(app core.App) {
+	var m Mailer
+
+	switch provider := strings.ToLower(os.Getenv("MAILER")); provider {
+	case "resend":
+		m = NewResend(os.Getenv("RESEND_API_KEY"), os.Getenv("RESEND_FROM_EMAIL"))
+	case "smtp":
+		m = &SMTP{App: app}
+	case "file":
+		dir := os.Getenv("MAILER_FILE_DIR")
+		if dir == "" {
+			dir = filepath.Join(app.DataDir(), "mail")
+		}
+		m = &File{Dir: dir}
+	case "":
+		if apiKey := os.Getenv("RESEND_API_KEY"); apiKey != "" {
+			m = NewResend(apiKey, os.Getenv("RESEND_FROM_EMAIL"))
+		} else {
+			m = &SMTP{App: app}
+		}
+	default:
+		log.Printf("[WARN] Unknown MAILER=%q, email disabled", provider)
+	}
+
+	switch {
+	case m == nil:
+		log.Println("[Mail] No email provider configured, email disabled")
+	case isAvailable(m):
+		log.Printf("[Mail] Using %s provider", m.Name())
+	default:
+		log.Printf("[Mail] Using %s provider once it is enabled in the settings", m.Name())
+	}
+
+	SetDefault(m)
+}
+
+// SetDefault replaces the mailer used by Send, e.g. with an Inbox in tests.
+func SetDefault(m Mailer) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultMailer = m
+}
+
+// Default returns the configured mailer, or nil when email is not set up
+// or the mailer is currently unavailable (e.g. SMTP is disabled).
+func Default() Mailer {
+	defaultMu.RLock()
+	m := defaultMailer
+	defaultMu.RUnlock()
+
+	if m == nil || !isAvailable(m) {
+		return nil
+	}
+	return m
+}
+
+func isAvailable(m Mailer) bool {
+	a, ok := m.(availability)
+	return !ok || a.Available()
+}
+
+// Send delivers the message with the default mailer. Failures are logged with
+// the provider's response, callers only need to handle the returned error.
+func Send(msg *Message) error {
+	m := Default()
+	if m == nil {
+		return ErrNotConfigured
+	}
+
+	if err := m.Send(msg); err != nil {
+		log.Printf("[Mail] %s failed to send %q to %s: %v", m.Name(), msg.Subject, strings.Join(msg.To, ", "), err)
+		return err
+	}
+	return nil
+}
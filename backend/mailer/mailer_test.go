@@ -0,0 +1,113 @@
+package mailer
+
+import (
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderPerLocale(t *testing.T) {
+	data := map[string]any{"Code": "123456", "ExpiresMinutes": 3}
+
+	cases := []struct {
+		locale  string
+		subject string
+		body    string
+	}{
+		{"ko", "[우물] 이메일 인증번호", "3분간 유효합니다"},
+		{"en", "[Oomool] Your verification code", "123456"},
+		{"fr", "[우물] 이메일 인증번호", "3분간 유효합니다"}, // 없는 언어는 한국어로 대체
+	}
+
+	for _, c := range cases {
+		subject, html, err := Render(c.locale, "verification", data)
+		if err != nil {
+			t.Fatalf("%s: Render failed: %v", c.locale, err)
+		}
+		if subject != c.subject {
+			t.Errorf("%s: subject = %q, want %q", c.locale, subject, c.subject)
+		}
+		if !strings.Contains(html, "123456") || !strings.Contains(html, c.body) {
+			t.Errorf("%s: unexpected html: %s", c.locale, html)
+		}
+	}
+}
+
+func TestSendUsesDefaultMailer(t *testing.T) {
+	inbox := &Inbox{}
+	SetDefault(inbox)
+	t.Cleanup(func() { SetDefault(nil) })
+
+	msg := &Message{To: []string{"user@example.com"}, Subject: "제목", HTML: "<p>본문</p>"}
+	if err := Send(msg); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	last := inbox.Last()
+	if last == nil || last.Subject != "제목" || last.To[0] != "user@example.com" {
+		t.Fatalf("inbox did not receive the message: %+v", last)
+	}
+	if got := len(inbox.Messages()); got != 1 {
+		t.Fatalf("inbox has %d messages, want 1", got)
+	}
+}
+
+func TestSendWithoutMailer(t *testing.T) {
+	SetDefault(nil)
+
+	if err := Send(&Message{To: []string{"user@example.com"}}); err != ErrNotConfigured {
+		t.Fatalf("Send error = %v, want ErrNotConfigured", err)
+	}
+}
+
+func TestFileEncodesSubject(t *testing.T) {
+	dir := t.TempDir()
+	sink := &File{Dir: dir}
+
+	subject := "[우물] 이메일 인증번호"
+	err := sink.Send(&Message{
+		From:    "noreply@example.com",
+		To:      []string{"user@example.com"},
+		Subject: subject,
+		HTML:    "<p>123456</p>",
+	})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one .eml file, got %v (%v)", files, err)
+	}
+	raw, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header, body, ok := strings.Cut(string(raw), "\r\n\r\n")
+	if !ok || body != "<p>123456</p>" {
+		t.Fatalf("unexpected message layout: %q", raw)
+	}
+
+	var encoded string
+	for _, line := range strings.Split(header, "\r\n") {
+		if value, ok := strings.CutPrefix(line, "Subject: "); ok {
+			encoded = value
+		}
+	}
+	for _, r := range encoded {
+		if r > 0x7f {
+			t.Fatalf("Subject header is not ASCII: %q", encoded)
+		}
+	}
+
+	decoded, err := new(mime.WordDecoder).DecodeHeader(encoded)
+	if err != nil {
+		t.Fatalf("Subject %q does not decode: %v", encoded, err)
+	}
+	if decoded != subject {
+		t.Fatalf("decoded subject = %q, want %q", decoded, subject)
+	}
+}
@@ -0,0 +1,80 @@
+package mailer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	resendEndpoint    = "https://api.resend.com/emails"
+	defaultFromResend = "우물 <onboarding@resend.dev>"
+)
+
+// Resend sends email through the Resend HTTP API. Error responses are
+// returned with their status and body.
+type Resend struct {
+	APIKey string
+	From   string
+	Client *http.Client
+}
+
+func NewResend(apiKey, from string) *Resend {
+	if from == "" {
+		from = defaultFromResend
+	}
+	return &Resend{
+		APIKey: apiKey,
+		From:   from,
+		Client: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (r *Resend) Name() string {
+	return "resend"
+}
+
+func (r *Resend) Send(msg *Message) error {
+	from := msg.From
+	if from == "" {
+		from = r.From
+	}
+
+	payload := map[string]any{
+		"from":    from,
+		"to":      msg.To,
+		"subject": msg.Subject,
+		"html":    msg.HTML,
+	}
+	if len(msg.Headers) > 0 {
+		payload["headers"] = msg.Headers
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, resendEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+r.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("resend: status %d: %s", resp.StatusCode, respBody)
+	}
+
+	return nil
+}
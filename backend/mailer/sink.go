@@ -0,0 +1,83 @@
+package mailer
+
+import (
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// File writes every message to Dir as an .eml file instead of sending it,
+// for local development. Open the files with any mail client or browser.
+type File struct {
+	Dir string
+}
+
+func (f *File) Name() string {
+	return "file"
+}
+
+func (f *File) Send(msg *Message) error {
+	if err := os.MkdirAll(f.Dir, 0o755); err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	if msg.From != "" {
+		fmt.Fprintf(&b, "From: %s\r\n", msg.From)
+	}
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(msg.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	for key, value := range msg.Headers {
+		fmt.Fprintf(&b, "%s: %s\r\n", key, value)
+	}
+	b.WriteString("MIME-Version: 1.0\r\nContent-Type: text/html; charset=UTF-8\r\n\r\n")
+	b.WriteString(msg.HTML)
+
+	name := time.Now().Format("20060102-150405.000000000") + "-" +
+		unsafeFileChars.ReplaceAllString(strings.Join(msg.To, "_"), "_") + ".eml"
+
+	return os.WriteFile(filepath.Join(f.Dir, name), []byte(b.String()), 0o644)
+}
+
+// Inbox keeps sent messages in memory, for tests.
+type Inbox struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func (i *Inbox) Name() string {
+	return "inbox"
+}
+
+func (i *Inbox) Send(msg *Message) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.messages = append(i.messages, *msg)
+	return nil
+}
+
+// Messages returns a copy of the messages received so far.
+func (i *Inbox) Messages() []Message {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return append([]Message(nil), i.messages...)
+}
+
+// Last returns the most recent message, or nil.
+func (i *Inbox) Last() *Message {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if len(i.messages) == 0 {
+		return nil
+	}
+	msg := i.messages[len(i.messages)-1]
+	return &msg
+}
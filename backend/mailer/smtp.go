@@ -0,0 +1,57 @@
+package mailer
+
+import (
+	"errors"
+	"net/mail"
+
+	"github.com/pocketbase/pocketbase/core"
+	pbmailer "github.com/pocketbase/pocketbase/tools/mailer"
+)
+
+// SMTP sends through the mail settings configured in the PocketBase admin
+// (Settings > Mail settings). Settings are read on every send, so enabling,
+// disabling or changing SMTP applies without a restart.
+type SMTP struct {
+	App core.App
+}
+
+func (s *SMTP) Name() string {
+	return "smtp"
+}
+
+// Available reports whether SMTP is currently enabled in the settings.
+func (s *SMTP) Available() bool {
+	return s.App.Settings().SMTP.Enabled
+}
+
+func (s *SMTP) Send(msg *Message) error {
+	meta := s.App.Settings().Meta
+	from := mail.Address{Name: meta.SenderName, Address: meta.SenderAddress}
+	if msg.From != "" {
+		addr, err := mail.ParseAddress(msg.From)
+		if err != nil {
+			return err
+		}
+		from = *addr
+	}
+	if from.Address == "" {
+		return errors.New("smtp: sender address is not configured")
+	}
+
+	to := make([]mail.Address, 0, len(msg.To))
+	for _, raw := range msg.To {
+		addr, err := mail.ParseAddress(raw)
+		if err != nil {
+			return err
+		}
+		to = append(to, *addr)
+	}
+
+	return s.App.NewMailClient().Send(&pbmailer.Message{
+		From:    from,
+		To:      to,
+		Subject: msg.Subject,
+		HTML:    msg.HTML,
+		Headers: msg.Headers,
+	})
+}
@@ -0,0 +1,60 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"io/fs"
+	"strings"
+)
+
+// 템플릿은 언어별 디렉터리에 있음 (templates/ko/digest.html 등)
+const defaultLocale = "ko"
+
+//go:embed templates
+var templateFS embed.FS
+
+var templateSets = loadTemplates()
+
+func loadTemplates() map[string]*template.Template {
+	funcs := template.FuncMap{"join": strings.Join}
+
+	entries, err := fs.ReadDir(templateFS, "templates")
+	if err != nil {
+		panic(err)
+	}
+
+	sets := map[string]*template.Template{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		pattern := "templates/" + entry.Name() + "/*.html"
+		sets[entry.Name()] = template.Must(template.New("").Funcs(funcs).ParseFS(templateFS, pattern))
+	}
+	return sets
+}
+
+// Render renders the named template (e.g. "digest") in the given locale,
+// falling back to Korean. Each template file defines "<name>.html" and,
+// for emails, "<name>.subject".
+func Render(locale, name string, data any) (subject, html string, err error) {
+	set, ok := templateSets[locale]
+	if !ok || set.Lookup(name+".html") == nil {
+		set = templateSets[defaultLocale]
+	}
+
+	var buf bytes.Buffer
+	if set.Lookup(name+".subject") != nil {
+		if err := set.ExecuteTemplate(&buf, name+".subject", data); err != nil {
+			return "", "", err
+		}
+		subject = strings.TrimSpace(buf.String())
+		buf.Reset()
+	}
+
+	if err := set.ExecuteTemplate(&buf, name+".html", data); err != nil {
+		return "", "", err
+	}
+	return subject, buf.String(), nil
+}
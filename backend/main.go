@@ -2,55 +2,146 @@ package main
 
 import (
 	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+	_ "time/tzdata" // 알림 방해 금지 시간대 계산용 (alpine 이미지에는 zoneinfo가 없음)
 
 	"minimo-backend/handlers"
 	"minimo-backend/hooks"
+	"minimo-backend/mailer"
 	"minimo-backend/middleware"
+	"minimo-backend/notify"
 
 	"github.com/pocketbase/pocketbase"
 	"github.com/pocketbase/pocketbase/apis"
 	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
 )
 
 func main() {
 	app := pocketbase.New()
 
+	// 모든 라우트가 공유하는 요청 제한 저장소 (DB 기반 슬라이딩 윈도우)
+	rateLimits := middleware.NewDBRateLimitStore(app)
+
 	hooks.RegisterNotificationHooks(app)
-	hooks.RegisterVerificationRoutes(app)
+	hooks.RegisterPushWorker(app)
+	hooks.RegisterRetentionJobs(app)
+	hooks.RegisterDigestJobs(app)
+	hooks.RegisterBadgeHooks(app)
+	hooks.RegisterPollJobs(app)
+	hooks.RegisterPublishingHooks(app)
+	hooks.RegisterRevisionHooks(app)
+	hooks.RegisterImageHooks(app)
+	hooks.RegisterVerificationRoutes(app, rateLimits)
 
 	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
-		if err := handlers.SetupFTS5(app); err != nil {
-			log.Printf("[WARN] FTS5 setup failed: %v", err)
-		}
-
 		// 컬렉션 스키마 보장 (JS 마이그레이션이 미적용된 필드 추가)
 		ensureAutodateFields(app)
 		ensureMissingFields(app)
 		ensureReportsCollection(app)
+		ensureReputationCollection(app)
+		ensureUserBadgesCollection(app)
+		ensurePollCollections(app)
+		ensurePublishedRules(app)
+		ensureRevisionsCollection(app)
+		ensureNotificationPreferencesCollection(app)
+		ensureQuietHoursFields(app)
+		ensureDigestFields(app)
+		ensureDevicesCollection(app)
+		ensurePushOutboxCollection(app)
+		ensureVerificationCodesCollection(app)
+
+		// IP별 요청 제한이 프록시 뒤의 실제 클라이언트 IP를 보도록 설정
+		ensureTrustedProxy(app)
+
+		// 메일 발송 설정 (PocketBase SMTP 설정을 읽으므로 부트스트랩 이후에 호출)
+		mailer.Setup(app)
+
+		// FTS 트리거가 status 필드를 참조하므로 스키마 보장 이후에 설정
+		if err := handlers.SetupFTS5(app); err != nil {
+			log.Printf("[WARN] FTS5 setup failed: %v", err)
+		}
 
 		requireAuth := apis.RequireAuth()
 		requireAdmin := middleware.RequireAdmin()
 
-		se.Router.POST("/api/community/toggle-like", handlers.HandleToggleLike(app)).Bind(requireAuth)
-		se.Router.POST("/api/community/toggle-curious", handlers.HandleToggleCurious(app)).Bind(requireAuth)
-		se.Router.POST("/api/community/toggle-follow", handlers.HandleToggleFollow(app)).Bind(requireAuth)
-		se.Router.POST("/api/community/increment-view", handlers.HandleIncrementView(app)).Bind(requireAuth)
+		// 커뮤니티 쓰기 API 요청 제한 (사용자당)
+		likeLimit := middleware.RateLimit(rateLimits, middleware.RateLimitRule{
+			Name:   "toggle_like",
+			Limit:  60,
+			Window: time.Minute,
+			Key:    middleware.ByUser,
+		})
+		followLimit := middleware.RateLimit(rateLimits, middleware.RateLimitRule{
+			Name:   "toggle_follow",
+			Limit:  30,
+			Window: time.Minute,
+			Key:    middleware.ByUser,
+		})
+		curiousLimit := middleware.RateLimit(rateLimits, middleware.RateLimitRule{
+			Name:   "toggle_curious",
+			Limit:  60,
+			Window: time.Minute,
+			Key:    middleware.ByUser,
+		})
+		bookmarkLimit := middleware.RateLimit(rateLimits, middleware.RateLimitRule{
+			Name:   "toggle_bookmark",
+			Limit:  60,
+			Window: time.Minute,
+			Key:    middleware.ByUser,
+		})
+		viewLimit := middleware.RateLimit(rateLimits, middleware.RateLimitRule{
+			Name:   "increment_view",
+			Limit:  120,
+			Window: time.Minute,
+			Key:    middleware.ByUser,
+		})
+
+		se.Router.POST("/api/community/toggle-like", handlers.HandleToggleLike(app)).Bind(requireAuth).BindFunc(likeLimit)
+		se.Router.POST("/api/community/toggle-curious", handlers.HandleToggleCurious(app)).Bind(requireAuth).BindFunc(curiousLimit)
+		se.Router.POST("/api/community/toggle-follow", handlers.HandleToggleFollow(app)).Bind(requireAuth).BindFunc(followLimit)
+		se.Router.POST("/api/community/increment-view", handlers.HandleIncrementView(app)).Bind(requireAuth).BindFunc(viewLimit)
 		se.Router.POST("/api/community/increment-comment-count", handlers.HandleIncrementCommentCount(app)).Bind(requireAuth)
 		se.Router.POST("/api/community/decrement-comment-count", handlers.HandleDecrementCommentCount(app)).Bind(requireAuth)
-		se.Router.POST("/api/community/toggle-bookmark", handlers.HandleToggleBookmark(app)).Bind(requireAuth)
+		se.Router.POST("/api/community/toggle-bookmark", handlers.HandleToggleBookmark(app)).Bind(requireAuth).BindFunc(bookmarkLimit)
 		se.Router.POST("/api/community/accept-answer", handlers.HandleAcceptAnswer(app)).Bind(requireAuth)
 
 		se.Router.GET("/api/community/posts", handlers.HandleGetPosts(app)).Bind(requireAuth)
 		se.Router.GET("/api/community/posts/{id}", handlers.HandleGetPost(app)).Bind(requireAuth)
+		se.Router.POST("/api/community/posts/{id}/poll", handlers.HandleCreatePoll(app)).Bind(requireAuth)
+		se.Router.PATCH("/api/community/posts/{id}/images/order", handlers.HandleReorderPostImages(app)).Bind(requireAuth)
+		se.Router.POST("/api/community/polls/{id}/vote", handlers.HandleVotePoll(app)).Bind(requireAuth)
 		se.Router.GET("/api/community/questions", handlers.HandleGetQuestions(app)).Bind(requireAuth)
 		se.Router.GET("/api/community/questions/{id}", handlers.HandleGetQuestion(app)).Bind(requireAuth)
+		se.Router.POST("/api/community/questions/similar", handlers.HandleSimilarQuestions(app)).Bind(requireAuth)
+		se.Router.GET("/api/community/questions/categories", handlers.HandleQuestionCategoryStats(app)).Bind(requireAuth)
 
 		se.Router.GET("/api/community/comments/{postId}", handlers.HandleGetCommentTree(app)).Bind(requireAuth)
 		se.Router.GET("/api/community/search", handlers.HandleSearch(app)).Bind(requireAuth)
 		se.Router.GET("/api/community/feed/trending", handlers.HandleTrendingFeed(app)).Bind(requireAuth)
+		se.Router.GET("/api/community/drafts", handlers.HandleGetDrafts(app)).Bind(requireAuth)
+		se.Router.GET("/api/community/revisions/{type}/{id}", handlers.HandleGetRevisions(app)).Bind(requireAuth)
+		se.Router.GET("/api/community/leaderboard", handlers.HandleLeaderboard(app)).Bind(requireAuth)
+		se.Router.GET("/api/community/users/{id}/profile", handlers.HandleGetUserProfile(app)).Bind(requireAuth)
 
+		se.Router.GET("/api/notifications", handlers.HandleGetNotifications(app)).Bind(requireAuth)
 		se.Router.POST("/api/notifications/mark-all-read", handlers.HandleMarkAllRead(app)).Bind(requireAuth)
+		se.Router.POST("/api/notifications/{id}/read", handlers.HandleMarkRead(app)).Bind(requireAuth)
+		se.Router.DELETE("/api/notifications/read", handlers.HandleDeleteReadNotifications(app)).Bind(requireAuth)
+		se.Router.DELETE("/api/notifications/{id}", handlers.HandleDeleteNotification(app)).Bind(requireAuth)
 		se.Router.GET("/api/notifications/unread-count", handlers.HandleUnreadCount(app)).Bind(requireAuth)
+		se.Router.GET("/api/notifications/preferences", handlers.HandleGetNotificationPreferences(app)).Bind(requireAuth)
+		se.Router.PATCH("/api/notifications/preferences", handlers.HandleUpdateNotificationPreferences(app)).Bind(requireAuth)
+		se.Router.POST("/api/devices", handlers.HandleRegisterDevice(app)).Bind(requireAuth)
+		se.Router.POST("/api/devices/unregister", handlers.HandleUnregisterDevice(app)).Bind(requireAuth)
+		se.Router.GET("/api/devices/vapid-public-key", handlers.HandleVapidPublicKey(hooks.WebPushPublicKey))
+
+		// 공개 공유 페이지 (인증 불필요)
+		se.Router.GET("/share/posts/{id}", handlers.HandleSharePost(app))
+		se.Router.GET("/share/questions/{id}", handlers.HandleShareQuestion(app))
 
 		// Admin API routes
 		se.Router.GET("/api/admin/stats/overview", handlers.HandleAdminStatsOverview(app)).Bind(requireAuth).BindFunc(requireAdmin)
@@ -70,6 +161,8 @@ func main() {
 		se.Router.PATCH("/api/admin/reports/{id}/resolve", handlers.HandleAdminResolveReport(app)).Bind(requireAuth).BindFunc(requireAdmin)
 		se.Router.GET("/api/admin/catalog/pending", handlers.HandleAdminGetPendingCatalog(app)).Bind(requireAuth).BindFunc(requireAdmin)
 		se.Router.PATCH("/api/admin/catalog/{id}/approve", handlers.HandleAdminApproveCatalog(app)).Bind(requireAuth).BindFunc(requireAdmin)
+		se.Router.GET("/api/admin/push/failures", handlers.HandleAdminGetPushFailures(app)).Bind(requireAuth).BindFunc(requireAdmin)
+		se.Router.POST("/api/admin/push/{id}/retry", handlers.HandleAdminRetryPush(app)).Bind(requireAuth).BindFunc(requireAdmin)
 
 		return se.Next()
 	})
@@ -163,6 +256,23 @@ func ensureMissingFields(app *pocketbase.PocketBase) {
 		}
 	}
 
+	// users: 알림과 이메일을 렌더링할 언어
+	if col, err := app.FindCollectionByNameOrId("users"); err == nil {
+		if col.Fields.GetByName("locale") == nil {
+			col.Fields.Add(&core.SelectField{
+				Id:        "select_locale",
+				Name:      "locale",
+				MaxSelect: 1,
+				Values:    notify.Locales,
+			})
+			if err := app.Save(col); err != nil {
+				log.Printf("[WARN] Failed to add locale field to users: %v", err)
+			} else {
+				log.Printf("[INFO] Added 'locale' field to users")
+			}
+		}
+	}
+
 	// community_posts: add status select field
 	if col, err := app.FindCollectionByNameOrId("community_posts"); err == nil {
 		if col.Fields.GetByName("status") == nil {
@@ -198,6 +308,156 @@ func ensureMissingFields(app *pocketbase.PocketBase) {
 			}
 		}
 	}
+	// community_posts, questions: 임시저장/예약 게시 지원
+	for _, name := range []string{"community_posts", "questions"} {
+		col, err := app.FindCollectionByNameOrId(name)
+		if err != nil {
+			continue
+		}
+
+		modified := false
+
+		if f, ok := col.Fields.GetByName("status").(*core.SelectField); ok {
+			for _, v := range []string{"draft", "scheduled"} {
+				if !slices.Contains(f.Values, v) {
+					f.Values = append(f.Values, v)
+					modified = true
+				}
+			}
+		}
+
+		if col.Fields.GetByName("publish_at") == nil {
+			col.Fields.Add(&core.DateField{
+				Id:   "date_publish_at",
+				Name: "publish_at",
+			})
+			modified = true
+		}
+
+		if modified {
+			if err := app.Save(col); err != nil {
+				log.Printf("[WARN] Failed to add draft/schedule support to %s: %v", name, err)
+			} else {
+				log.Printf("[INFO] Added draft/schedule support to %s", name)
+			}
+		}
+	}
+	// community_posts: 여러 장의 이미지를 순서대로 저장
+	if col, err := app.FindCollectionByNameOrId("community_posts"); err == nil {
+		if col.Fields.GetByName("images") == nil {
+			col.Fields.Add(&core.FileField{
+				Id:        "file_images",
+				Name:      "images",
+				MaxSelect: 10,
+				MaxSize:   5 << 20,
+				MimeTypes: []string{"image/jpeg", "image/png", "image/webp"},
+				Thumbs:    []string{"400x300"},
+			})
+			if err := app.Save(col); err != nil {
+				log.Printf("[WARN] Failed to add images field to community_posts: %v", err)
+			} else {
+				log.Printf("[INFO] Added 'images' field to community_posts")
+			}
+		}
+	}
+
+	// 커뮤니티 콘텐츠: 수정 시각 필드 추가
+	for _, name := range []string{"community_posts", "questions", "comments", "answers"} {
+		col, err := app.FindCollectionByNameOrId(name)
+		if err != nil || col.Fields.GetByName("edited_at") != nil {
+			continue
+		}
+
+		col.Fields.Add(&core.DateField{
+			Id:   "date_edited_at",
+			Name: "edited_at",
+		})
+		if err := app.Save(col); err != nil {
+			log.Printf("[WARN] Failed to add edited_at field to %s: %v", name, err)
+		} else {
+			log.Printf("[INFO] Added 'edited_at' field to %s", name)
+		}
+	}
+
+	// notifications: 같은 대상의 알림 묶음 처리 및 중복 방지용 필드 추가
+	if col, err := app.FindCollectionByNameOrId("notifications"); err == nil {
+		modified := false
+
+		if col.Fields.GetByName("actor_count") == nil {
+			col.Fields.Add(&core.NumberField{
+				Id:      "number_actor_count",
+				Name:    "actor_count",
+				OnlyInt: true,
+			})
+			modified = true
+		}
+		if col.Fields.GetByName("actor_ids") == nil {
+			col.Fields.Add(&core.JSONField{
+				Id:   "json_actor_ids",
+				Name: "actor_ids",
+			})
+			modified = true
+		}
+		if col.Fields.GetByName("recent_actors") == nil {
+			if usersCol, err := app.FindCollectionByNameOrId("users"); err == nil {
+				col.Fields.Add(&core.RelationField{
+					Id:           "relation_recent_actors",
+					Name:         "recent_actors",
+					CollectionId: usersCol.Id,
+					MaxSelect:    3,
+				})
+				modified = true
+			}
+		}
+		if col.Fields.GetByName("last_pushed_at") == nil {
+			col.Fields.Add(&core.DateField{
+				Id:   "date_last_pushed_at",
+				Name: "last_pushed_at",
+			})
+			modified = true
+		}
+
+		if col.Fields.GetByName("push_status") == nil {
+			col.Fields.Add(&core.SelectField{
+				Id:        "select_push_status",
+				Name:      "push_status",
+				MaxSelect: 1,
+				Values:    []string{"pending", "sent", "failed"},
+			})
+			modified = true
+		}
+		if col.Fields.GetByName("dedupe_key") == nil {
+			col.Fields.Add(&core.TextField{
+				Id:   "text_dedupe_key",
+				Name: "dedupe_key",
+			})
+			col.AddIndex("idx_notifications_dedupe_key", false, "dedupe_key, created", "")
+			modified = true
+		}
+		// 원본 이벤트 (다른 언어로 다시 렌더링할 때 사용)
+		if col.Fields.GetByName("template") == nil {
+			col.Fields.Add(&core.TextField{
+				Id:   "text_notification_template",
+				Name: "template",
+			})
+			modified = true
+		}
+		if col.Fields.GetByName("event_data") == nil {
+			col.Fields.Add(&core.JSONField{
+				Id:   "json_event_data",
+				Name: "event_data",
+			})
+			modified = true
+		}
+
+		if modified {
+			if err := app.Save(col); err != nil {
+				log.Printf("[WARN] Failed to add fields to notifications: %v", err)
+			} else {
+				log.Printf("[INFO] Added missing fields to notifications")
+			}
+		}
+	}
 }
 
 // ensureReportsCollection creates the reports collection if it doesn't exist.
@@ -289,6 +549,789 @@ func ensureReportsCollection(app *pocketbase.PocketBase) {
 	}
 }
 
+// ensureReputationCollection creates the append-only reputation ledger.
+func ensureReputationCollection(app *pocketbase.PocketBase) {
+	if _, err := app.FindCollectionByNameOrId("reputation_events"); err == nil {
+		return
+	}
+
+	collection := core.NewBaseCollection("reputation_events")
+
+	usersCol, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		log.Printf("[WARN] Failed to find users collection for reputation_events: %v", err)
+		return
+	}
+
+	collection.Fields.Add(&core.RelationField{
+		Id:            "relation_rep_user",
+		Name:          "user",
+		Required:      true,
+		CollectionId:  usersCol.Id,
+		MaxSelect:     1,
+		CascadeDelete: true,
+	})
+	collection.Fields.Add(&core.NumberField{
+		Id:      "number_points",
+		Name:    "points",
+		OnlyInt: true,
+	})
+	collection.Fields.Add(&core.SelectField{
+		Id:        "select_rep_reason",
+		Name:      "reason",
+		Required:  true,
+		MaxSelect: 1,
+		Values:    []string{"answer_accepted", "like_received", "curious_received"},
+	})
+	collection.Fields.Add(&core.TextField{
+		Id:   "text_target_id",
+		Name: "target_id",
+	})
+	collection.Fields.Add(&core.TextField{
+		Id:   "text_target_type",
+		Name: "target_type",
+	})
+	collection.Fields.Add(&core.RelationField{
+		Id:           "relation_rep_actor",
+		Name:         "actor",
+		Required:     false,
+		CollectionId: usersCol.Id,
+		MaxSelect:    1,
+	})
+	collection.Fields.Add(&core.AutodateField{
+		Id:       "autodate_created",
+		Name:     "created",
+		OnCreate: true,
+	})
+	collection.AddIndex("idx_reputation_user_created", false, "user, created", "")
+
+	// 원장은 서버에서만 기록하며 수정/삭제 불가
+	collection.ListRule = types.Pointer("@request.auth.id = user")
+	collection.ViewRule = types.Pointer("@request.auth.id = user")
+
+	if err := app.Save(collection); err != nil {
+		log.Printf("[WARN] Failed to create reputation_events collection: %v", err)
+	} else {
+		log.Printf("[INFO] Created 'reputation_events' collection")
+	}
+}
+
+// ensureUserBadgesCollection creates the collection of badges awarded to users.
+func ensureUserBadgesCollection(app *pocketbase.PocketBase) {
+	if _, err := app.FindCollectionByNameOrId("user_badges"); err == nil {
+		return
+	}
+
+	collection := core.NewBaseCollection("user_badges")
+
+	usersCol, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		log.Printf("[WARN] Failed to find users collection for user_badges: %v", err)
+		return
+	}
+
+	collection.Fields.Add(&core.RelationField{
+		Id:            "relation_badge_user",
+		Name:          "user",
+		Required:      true,
+		CollectionId:  usersCol.Id,
+		MaxSelect:     1,
+		CascadeDelete: true,
+	})
+	collection.Fields.Add(&core.TextField{
+		Id:       "text_badge",
+		Name:     "badge",
+		Required: true,
+	})
+	collection.Fields.Add(&core.TextField{
+		Id:   "text_badge_name",
+		Name: "name",
+	})
+	collection.Fields.Add(&core.TextField{
+		Id:   "text_badge_description",
+		Name: "description",
+	})
+	collection.Fields.Add(&core.AutodateField{
+		Id:       "autodate_created",
+		Name:     "created",
+		OnCreate: true,
+	})
+	collection.AddIndex("idx_user_badges_user_badge", true, "user, badge", "")
+
+	collection.ListRule = types.Pointer("")
+	collection.ViewRule = types.Pointer("")
+
+	if err := app.Save(collection); err != nil {
+		log.Printf("[WARN] Failed to create user_badges collection: %v", err)
+	} else {
+		log.Printf("[INFO] Created 'user_badges' collection")
+	}
+}
+
+// ensurePollCollections creates the polls, poll_options and poll_votes collections.
+func ensurePollCollections(app *pocketbase.PocketBase) {
+	usersCol, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		log.Printf("[WARN] Failed to find users collection for polls: %v", err)
+		return
+	}
+	postsCol, err := app.FindCollectionByNameOrId("community_posts")
+	if err != nil {
+		log.Printf("[WARN] Failed to find community_posts collection for polls: %v", err)
+		return
+	}
+
+	pollsCol, err := app.FindCollectionByNameOrId("polls")
+	if err != nil {
+		pollsCol = core.NewBaseCollection("polls")
+		pollsCol.Fields.Add(&core.RelationField{
+			Id:            "relation_poll_post",
+			Name:          "post",
+			Required:      true,
+			CollectionId:  postsCol.Id,
+			MaxSelect:     1,
+			CascadeDelete: true,
+		})
+		pollsCol.Fields.Add(&core.BoolField{
+			Id:   "bool_multiple",
+			Name: "multiple",
+		})
+		pollsCol.Fields.Add(&core.DateField{
+			Id:   "date_closes_at",
+			Name: "closes_at",
+		})
+		pollsCol.Fields.Add(&core.BoolField{
+			Id:   "bool_closed",
+			Name: "closed",
+		})
+		pollsCol.Fields.Add(&core.AutodateField{
+			Id:       "autodate_created",
+			Name:     "created",
+			OnCreate: true,
+		})
+		pollsCol.Fields.Add(&core.AutodateField{
+			Id:       "autodate_updated",
+			Name:     "updated",
+			OnCreate: true,
+			OnUpdate: true,
+		})
+		pollsCol.AddIndex("idx_polls_post", true, "post", "")
+		pollsCol.AddIndex("idx_polls_closes_at", false, "closed, closes_at", "")
+		pollsCol.ListRule = types.Pointer("")
+		pollsCol.ViewRule = types.Pointer("")
+
+		if err := app.Save(pollsCol); err != nil {
+			log.Printf("[WARN] Failed to create polls collection: %v", err)
+			return
+		}
+		log.Printf("[INFO] Created 'polls' collection")
+	}
+
+	optionsCol, err := app.FindCollectionByNameOrId("poll_options")
+	if err != nil {
+		optionsCol = core.NewBaseCollection("poll_options")
+		optionsCol.Fields.Add(&core.RelationField{
+			Id:            "relation_option_poll",
+			Name:          "poll",
+			Required:      true,
+			CollectionId:  pollsCol.Id,
+			MaxSelect:     1,
+			CascadeDelete: true,
+		})
+		optionsCol.Fields.Add(&core.TextField{
+			Id:       "text_label",
+			Name:     "label",
+			Required: true,
+			Max:      100,
+		})
+		optionsCol.Fields.Add(&core.NumberField{
+			Id:      "number_position",
+			Name:    "position",
+			OnlyInt: true,
+		})
+		optionsCol.Fields.Add(&core.NumberField{
+			Id:      "number_vote_count",
+			Name:    "vote_count",
+			OnlyInt: true,
+		})
+		optionsCol.AddIndex("idx_poll_options_poll", false, "poll, position", "")
+		optionsCol.ListRule = types.Pointer("")
+		optionsCol.ViewRule = types.Pointer("")
+
+		if err := app.Save(optionsCol); err != nil {
+			log.Printf("[WARN] Failed to create poll_options collection: %v", err)
+			return
+		}
+		log.Printf("[INFO] Created 'poll_options' collection")
+	}
+
+	if _, err := app.FindCollectionByNameOrId("poll_votes"); err == nil {
+		return
+	}
+
+	votesCol := core.NewBaseCollection("poll_votes")
+	votesCol.Fields.Add(&core.RelationField{
+		Id:            "relation_vote_poll",
+		Name:          "poll",
+		Required:      true,
+		CollectionId:  pollsCol.Id,
+		MaxSelect:     1,
+		CascadeDelete: true,
+	})
+	votesCol.Fields.Add(&core.RelationField{
+		Id:            "relation_vote_option",
+		Name:          "option",
+		Required:      true,
+		CollectionId:  optionsCol.Id,
+		MaxSelect:     1,
+		CascadeDelete: true,
+	})
+	votesCol.Fields.Add(&core.RelationField{
+		Id:            "relation_vote_user",
+		Name:          "user",
+		Required:      true,
+		CollectionId:  usersCol.Id,
+		MaxSelect:     1,
+		CascadeDelete: true,
+	})
+	// 단일 선택 투표는 "single", 복수 선택은 선택지 id (사용자당 1표를 DB에서 보장)
+	votesCol.Fields.Add(&core.TextField{
+		Id:       "text_vote_slot",
+		Name:     "slot",
+		Required: true,
+		Max:      50,
+	})
+	votesCol.Fields.Add(&core.AutodateField{
+		Id:       "autodate_created",
+		Name:     "created",
+		OnCreate: true,
+	})
+	votesCol.AddIndex("idx_poll_votes_user_option", true, "poll, user, option", "")
+	votesCol.AddIndex("idx_poll_votes_user_slot", true, "poll, user, slot", "")
+
+	if err := app.Save(votesCol); err != nil {
+		log.Printf("[WARN] Failed to create poll_votes collection: %v", err)
+	} else {
+		log.Printf("[INFO] Created 'poll_votes' collection")
+	}
+}
+
+// publishedListRule hides drafts and scheduled items from everyone but their owner.
+const publishedListRule = "(status != 'draft' && status != 'scheduled') || owner = @request.auth.id"
+
+// ensurePublishedRules applies publishedListRule to the list and view rules of
+// community posts and questions, which the client reads through getList.
+// Only rules still at the public baseline ("") are replaced, so a rule changed
+// in the dashboard is kept across restarts.
+func ensurePublishedRules(app *pocketbase.PocketBase) {
+	for _, name := range []string{"community_posts", "questions"} {
+		col, err := app.FindCollectionByNameOrId(name)
+		if err != nil {
+			continue
+		}
+
+		changed := false
+		if col.ListRule != nil && *col.ListRule == "" {
+			col.ListRule = types.Pointer(publishedListRule)
+			changed = true
+		}
+		if col.ViewRule != nil && *col.ViewRule == "" {
+			col.ViewRule = types.Pointer(publishedListRule)
+			changed = true
+		}
+		if !changed {
+			continue
+		}
+
+		if err := app.Save(col); err != nil {
+			log.Printf("[WARN] Failed to set published rules on %s: %v", name, err)
+		} else {
+			log.Printf("[INFO] Set published list/view rules on %s", name)
+		}
+	}
+}
+
+// ensureRevisionsCollection creates the edit history of community content.
+func ensureRevisionsCollection(app *pocketbase.PocketBase) {
+	if _, err := app.FindCollectionByNameOrId("content_revisions"); err == nil {
+		return
+	}
+
+	collection := core.NewBaseCollection("content_revisions")
+
+	collection.Fields.Add(&core.TextField{
+		Id:       "text_target_id",
+		Name:     "target_id",
+		Required: true,
+	})
+	collection.Fields.Add(&core.SelectField{
+		Id:        "select_target_type",
+		Name:      "target_type",
+		Required:  true,
+		MaxSelect: 1,
+		Values:    []string{"post", "question", "comment", "answer"},
+	})
+	collection.Fields.Add(&core.TextField{
+		Id:   "text_title",
+		Name: "title",
+	})
+	collection.Fields.Add(&core.TextField{
+		Id:   "text_content",
+		Name: "content",
+	})
+	collection.Fields.Add(&core.DateField{
+		Id:   "date_written_at",
+		Name: "written_at",
+	})
+	collection.Fields.Add(&core.AutodateField{
+		Id:       "autodate_created",
+		Name:     "created",
+		OnCreate: true,
+	})
+	collection.AddIndex("idx_revisions_target", false, "target_id, target_type, created", "")
+
+	// 이력 조회는 작성자/관리자 확인이 필요하므로 커스텀 API로만 제공
+	if err := app.Save(collection); err != nil {
+		log.Printf("[WARN] Failed to create content_revisions collection: %v", err)
+	} else {
+		log.Printf("[INFO] Created 'content_revisions' collection")
+	}
+}
+
+// ensureNotificationPreferencesCollection creates the per-user notification settings.
+// Types listed in in_app_muted / push_muted are not delivered on that channel;
+// users without a record receive everything.
+func ensureNotificationPreferencesCollection(app *pocketbase.PocketBase) {
+	if _, err := app.FindCollectionByNameOrId("notification_preferences"); err == nil {
+		return
+	}
+
+	usersCol, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		log.Printf("[WARN] Failed to find users collection for notification_preferences: %v", err)
+		return
+	}
+
+	notificationTypes := []string{"like", "comment", "follow", "answer", "mention", "system"}
+
+	collection := core.NewBaseCollection("notification_preferences")
+
+	collection.Fields.Add(&core.RelationField{
+		Id:            "relation_pref_user",
+		Name:          "user",
+		Required:      true,
+		CollectionId:  usersCol.Id,
+		MaxSelect:     1,
+		CascadeDelete: true,
+	})
+	collection.Fields.Add(&core.SelectField{
+		Id:        "select_in_app_muted",
+		Name:      "in_app_muted",
+		MaxSelect: len(notificationTypes),
+		Values:    notificationTypes,
+	})
+	collection.Fields.Add(&core.SelectField{
+		Id:        "select_push_muted",
+		Name:      "push_muted",
+		MaxSelect: len(notificationTypes),
+		Values:    notificationTypes,
+	})
+	collection.Fields.Add(&core.AutodateField{
+		Id:       "autodate_created",
+		Name:     "created",
+		OnCreate: true,
+	})
+	collection.Fields.Add(&core.AutodateField{
+		Id:       "autodate_updated",
+		Name:     "updated",
+		OnCreate: true,
+		OnUpdate: true,
+	})
+	collection.AddIndex("idx_notification_preferences_user", true, "user", "")
+
+	collection.ListRule = types.Pointer("@request.auth.id = user")
+	collection.ViewRule = types.Pointer("@request.auth.id = user")
+
+	if err := app.Save(collection); err != nil {
+		log.Printf("[WARN] Failed to create notification_preferences collection: %v", err)
+	} else {
+		log.Printf("[INFO] Created 'notification_preferences' collection")
+	}
+}
+
+// ensureQuietHoursFields adds the quiet hours settings to notification_preferences.
+// quiet_start / quiet_end are "HH:MM" in the user's timezone (IANA name).
+func ensureQuietHoursFields(app *pocketbase.PocketBase) {
+	col, err := app.FindCollectionByNameOrId("notification_preferences")
+	if err != nil || col.Fields.GetByName("quiet_hours_enabled") != nil {
+		return
+	}
+
+	col.Fields.Add(&core.BoolField{
+		Id:   "bool_quiet_hours_enabled",
+		Name: "quiet_hours_enabled",
+	})
+	col.Fields.Add(&core.TextField{
+		Id:      "text_quiet_start",
+		Name:    "quiet_start",
+		Pattern: `^([01]\d|2[0-3]):[0-5]\d$`,
+	})
+	col.Fields.Add(&core.TextField{
+		Id:      "text_quiet_end",
+		Name:    "quiet_end",
+		Pattern: `^([01]\d|2[0-3]):[0-5]\d$`,
+	})
+	col.Fields.Add(&core.TextField{
+		Id:   "text_timezone",
+		Name: "timezone",
+		Max:  64,
+	})
+
+	if err := app.Save(col); err != nil {
+		log.Printf("[WARN] Failed to add quiet hours fields to notification_preferences: %v", err)
+	} else {
+		log.Printf("[INFO] Added quiet hours fields to notification_preferences")
+	}
+}
+
+// ensureDigestFields adds the weekly email digest opt-in to notification_preferences.
+// digest_token identifies the unsubscribe link and is never exposed through the API.
+func ensureDigestFields(app *pocketbase.PocketBase) {
+	col, err := app.FindCollectionByNameOrId("notification_preferences")
+	if err != nil || col.Fields.GetByName("email_digest") != nil {
+		return
+	}
+
+	col.Fields.Add(&core.BoolField{
+		Id:   "bool_email_digest",
+		Name: "email_digest",
+	})
+	col.Fields.Add(&core.TextField{
+		Id:     "text_digest_token",
+		Name:   "digest_token",
+		Hidden: true,
+	})
+	col.Fields.Add(&core.DateField{
+		Id:   "date_digest_sent_at",
+		Name: "digest_sent_at",
+	})
+	col.AddIndex("idx_notification_preferences_digest_token", false, "digest_token", "")
+
+	if err := app.Save(col); err != nil {
+		log.Printf("[WARN] Failed to add digest fields to notification_preferences: %v", err)
+	} else {
+		log.Printf("[INFO] Added digest fields to notification_preferences")
+	}
+}
+
+// ensureDevicesCollection creates the push token registry.
+// A user may have several devices; a token belongs to exactly one device.
+func ensureDevicesCollection(app *pocketbase.PocketBase) {
+	if _, err := app.FindCollectionByNameOrId("devices"); err == nil {
+		return
+	}
+
+	usersCol, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		log.Printf("[WARN] Failed to find users collection for devices: %v", err)
+		return
+	}
+
+	collection := core.NewBaseCollection("devices")
+
+	collection.Fields.Add(&core.RelationField{
+		Id:            "relation_device_user",
+		Name:          "user",
+		Required:      true,
+		CollectionId:  usersCol.Id,
+		MaxSelect:     1,
+		CascadeDelete: true,
+	})
+	collection.Fields.Add(&core.TextField{
+		Id:       "text_device_token",
+		Name:     "token",
+		Required: true,
+	})
+	collection.Fields.Add(&core.SelectField{
+		Id:        "select_device_platform",
+		Name:      "platform",
+		Required:  true,
+		MaxSelect: 1,
+		Values:    []string{"ios", "android", "web"},
+	})
+	collection.Fields.Add(&core.TextField{
+		Id:   "text_app_version",
+		Name: "app_version",
+		Max:  50,
+	})
+	collection.Fields.Add(&core.DateField{
+		Id:   "date_last_seen",
+		Name: "last_seen",
+	})
+	collection.Fields.Add(&core.AutodateField{
+		Id:       "autodate_created",
+		Name:     "created",
+		OnCreate: true,
+	})
+	collection.Fields.Add(&core.AutodateField{
+		Id:       "autodate_updated",
+		Name:     "updated",
+		OnCreate: true,
+		OnUpdate: true,
+	})
+	collection.AddIndex("idx_devices_token", true, "token", "")
+	collection.AddIndex("idx_devices_user", false, "user", "")
+
+	// 토큰 등록/해제는 커스텀 API로만 허용
+	collection.ListRule = types.Pointer("@request.auth.id = user")
+	collection.ViewRule = types.Pointer("@request.auth.id = user")
+
+	if err := app.Save(collection); err != nil {
+		log.Printf("[WARN] Failed to create devices collection: %v", err)
+	} else {
+		log.Printf("[INFO] Created 'devices' collection")
+	}
+}
+
+// ensurePushOutboxCollection creates the persisted push queue.
+// Each row is one push to one device token, retried by the push worker.
+func ensurePushOutboxCollection(app *pocketbase.PocketBase) {
+	if existing, err := app.FindCollectionByNameOrId("push_outbox"); err == nil {
+		ensurePushOutboxPlatform(app, existing)
+		return
+	}
+
+	usersCol, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		log.Printf("[WARN] Failed to find users collection for push_outbox: %v", err)
+		return
+	}
+
+	collection := core.NewBaseCollection("push_outbox")
+
+	collection.Fields.Add(&core.RelationField{
+		Id:            "relation_outbox_user",
+		Name:          "user",
+		Required:      true,
+		CollectionId:  usersCol.Id,
+		MaxSelect:     1,
+		CascadeDelete: true,
+	})
+	collection.Fields.Add(&core.TextField{
+		Id:       "text_outbox_token",
+		Name:     "token",
+		Required: true,
+	})
+	// 등록 시점의 기기 플랫폼 (기기가 삭제된 뒤에도 올바른 발송 경로 사용)
+	collection.Fields.Add(&core.TextField{
+		Id:   "text_outbox_platform",
+		Name: "platform",
+	})
+	collection.Fields.Add(&core.SelectField{
+		Id:        "select_outbox_kind",
+		Name:      "kind",
+		Required:  true,
+		MaxSelect: 1,
+		Values:    []string{"alert", "silent"},
+	})
+	collection.Fields.Add(&core.TextField{
+		Id:   "text_outbox_notification",
+		Name: "notification_id",
+	})
+	collection.Fields.Add(&core.TextField{
+		Id:   "text_outbox_title",
+		Name: "title",
+	})
+	collection.Fields.Add(&core.TextField{
+		Id:   "text_outbox_body",
+		Name: "body",
+	})
+	collection.Fields.Add(&core.JSONField{
+		Id:   "json_outbox_data",
+		Name: "data",
+	})
+	collection.Fields.Add(&core.SelectField{
+		Id:        "select_outbox_status",
+		Name:      "status",
+		Required:  true,
+		MaxSelect: 1,
+		Values:    []string{"pending", "sent", "dead"},
+	})
+	collection.Fields.Add(&core.NumberField{
+		Id:      "number_outbox_attempts",
+		Name:    "attempts",
+		OnlyInt: true,
+	})
+	collection.Fields.Add(&core.DateField{
+		Id:   "date_next_attempt_at",
+		Name: "next_attempt_at",
+	})
+	collection.Fields.Add(&core.TextField{
+		Id:   "text_last_error",
+		Name: "last_error",
+	})
+	collection.Fields.Add(&core.DateField{
+		Id:   "date_sent_at",
+		Name: "sent_at",
+	})
+	collection.Fields.Add(&core.AutodateField{
+		Id:       "autodate_created",
+		Name:     "created",
+		OnCreate: true,
+	})
+	collection.Fields.Add(&core.AutodateField{
+		Id:       "autodate_updated",
+		Name:     "updated",
+		OnCreate: true,
+		OnUpdate: true,
+	})
+	collection.AddIndex("idx_push_outbox_status", false, "status, next_attempt_at", "")
+	collection.AddIndex("idx_push_outbox_notification", false, "notification_id", "")
+
+	// 관리자 API로만 조회 (API 규칙 없음)
+	if err := app.Save(collection); err != nil {
+		log.Printf("[WARN] Failed to create push_outbox collection: %v", err)
+	} else {
+		log.Printf("[INFO] Created 'push_outbox' collection")
+	}
+}
+
+// ensurePushOutboxPlatform adds the platform field to an existing push_outbox
+// and fills it for queued items from their device, or from the token format.
+func ensurePushOutboxPlatform(app *pocketbase.PocketBase, collection *core.Collection) {
+	if collection.Fields.GetByName("platform") != nil {
+		return
+	}
+
+	collection.Fields.Add(&core.TextField{
+		Id:   "text_outbox_platform",
+		Name: "platform",
+	})
+	if err := app.Save(collection); err != nil {
+		log.Printf("[WARN] Failed to add platform field to push_outbox: %v", err)
+		return
+	}
+
+	_, err := app.DB().NewQuery(`
+		UPDATE push_outbox SET platform = CASE
+			WHEN token LIKE '{%' THEN 'web'
+			ELSE COALESCE((SELECT d.platform FROM devices d WHERE d.token = push_outbox.token), '')
+		END
+	`).Execute()
+	if err != nil {
+		log.Printf("[WARN] Failed to backfill push_outbox platform: %v", err)
+	} else {
+		log.Printf("[INFO] Added 'platform' field to push_outbox")
+	}
+}
+
+// ensureVerificationCodesCollection creates verification_codes, or upgrades a
+// collection created by hand: codes are stored only as a keyed hash
+// (code_hash) and attempts counts failed verifications.
+func ensureVerificationCodesCollection(app *pocketbase.PocketBase) {
+	collection, err := app.FindCollectionByNameOrId("verification_codes")
+	if err != nil {
+		collection = core.NewBaseCollection("verification_codes")
+		collection.Fields.Add(&core.EmailField{
+			Id:       "email_verification_email",
+			Name:     "email",
+			Required: true,
+		})
+		collection.Fields.Add(&core.TextField{
+			Id:   "text_verification_expires_at",
+			Name: "expires_at",
+		})
+		collection.Fields.Add(&core.BoolField{
+			Id:   "bool_verification_verified",
+			Name: "verified",
+		})
+		collection.Fields.Add(&core.AutodateField{
+			Id:       "autodate_created",
+			Name:     "created",
+			OnCreate: true,
+		})
+		collection.AddIndex("idx_verification_codes_email", false, "email", "")
+	}
+
+	modified := collection.IsNew()
+
+	if collection.Fields.GetByName("code_hash") == nil {
+		collection.Fields.Add(&core.TextField{
+			Id:     "text_verification_code_hash",
+			Name:   "code_hash",
+			Hidden: true,
+		})
+		modified = true
+	}
+	if collection.Fields.GetByName("attempts") == nil {
+		collection.Fields.Add(&core.NumberField{
+			Id:      "number_verification_attempts",
+			Name:    "attempts",
+			OnlyInt: true,
+		})
+		modified = true
+	}
+	// 평문 코드는 더 이상 저장하지 않음
+	if field, ok := collection.Fields.GetByName("code").(*core.TextField); ok && (field.Required || !field.Hidden) {
+		field.Required = false
+		field.Hidden = true
+		modified = true
+	}
+
+	if !modified {
+		return
+	}
+
+	// 서버에서만 사용 (API 규칙 없음)
+	if err := app.Save(collection); err != nil {
+		log.Printf("[WARN] Failed to save verification_codes collection: %v", err)
+		return
+	}
+	log.Printf("[INFO] Updated 'verification_codes' collection")
+
+	if collection.Fields.GetByName("code") != nil {
+		_, _ = app.DB().NewQuery("UPDATE verification_codes SET code = '' WHERE code != ''").Execute()
+	}
+}
+
+// ensureTrustedProxy configures the header e.RealIP() reads the client IP
+// from. Behind the Fly.io proxy every request comes from the proxy address, so
+// without it all clients would share the per-IP rate limits.
+// TRUSTED_PROXY_HEADERS (comma separated) is applied on every boot and wins
+// over the admin settings; without it the admin settings are kept, or
+// Fly-Client-IP is used when running on Fly.io.
+func ensureTrustedProxy(app *pocketbase.PocketBase) {
+	settings := app.Settings()
+
+	var headers []string
+	if raw := os.Getenv("TRUSTED_PROXY_HEADERS"); raw != "" {
+		for _, header := range strings.Split(raw, ",") {
+			if header = strings.TrimSpace(header); header != "" {
+				headers = append(headers, header)
+			}
+		}
+	} else if len(settings.TrustedProxy.Headers) > 0 {
+		return
+	} else if os.Getenv("FLY_APP_NAME") != "" {
+		headers = []string{"Fly-Client-IP"}
+	}
+
+	if len(headers) == 0 {
+		log.Println("[WARN] No trusted proxy headers configured; per-IP rate limits use the direct peer address. Set TRUSTED_PROXY_HEADERS when running behind a proxy.")
+		return
+	}
+	if slices.Equal(settings.TrustedProxy.Headers, headers) {
+		return
+	}
+
+	settings.TrustedProxy.Headers = headers
+	if err := app.Save(settings); err != nil {
+		log.Printf("[WARN] Failed to save trusted proxy headers: %v", err)
+	} else {
+		log.Printf("[INFO] Trusting proxy headers %v for client IPs", headers)
+	}
+}
+
 func ensureAutodateFields(app *pocketbase.PocketBase) {
 	collections := []string{
 		"community_posts", "questions", "aquariums", "creatures",
@@ -0,0 +1,227 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/dbx"
+)
+
+// RateLimitStore counts the hits of a key in a sliding window.
+type RateLimitStore interface {
+	// Allow records a hit for key unless the key already has limit hits in
+	// the last window. When rejected, retryAfter is the time until the
+	// oldest hit leaves the window.
+	Allow(key string, limit int, window time.Duration) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// KeyFunc returns the identity a request is counted against.
+type KeyFunc func(e *core.RequestEvent) string
+
+// RateLimitRule limits a route to Limit requests per Window for each key.
+type RateLimitRule struct {
+	Name    string
+	Limit   int
+	Window  time.Duration
+	Key     KeyFunc
+	Message string
+}
+
+// RateLimit returns a middleware that rejects requests over the rule's limit
+// with 429 and a Retry-After header. Store errors let the request through.
+// Rules keyed by user must be bound after RequireAuth().
+func RateLimit(store RateLimitStore, rule RateLimitRule) func(e *core.RequestEvent) error {
+	keyFunc := rule.Key
+	if keyFunc == nil {
+		keyFunc = ByIP
+	}
+	message := rule.Message
+	if message == "" {
+		message = "Too many requests. Please try again later."
+	}
+
+	return func(e *core.RequestEvent) error {
+		key := rule.Name + ":" + keyFunc(e)
+
+		allowed, retryAfter, err := store.Allow(key, rule.Limit, rule.Window)
+		if err != nil {
+			log.Printf("[WARN] Rate limit check %s failed: %v", rule.Name, err)
+			return e.Next()
+		}
+		if !allowed {
+			seconds := int(retryAfter.Round(time.Second).Seconds())
+			e.Response.Header().Set("Retry-After", strconv.Itoa(max(seconds, 1)))
+			return apis.NewTooManyRequestsError(message, nil)
+		}
+
+		return e.Next()
+	}
+}
+
+// ByIP counts requests per client IP. Behind a proxy the client IP is only
+// known when the proxy header is trusted (see ensureTrustedProxy in main.go).
+func ByIP(e *core.RequestEvent) string {
+	return "ip:" + e.RealIP()
+}
+
+// ByUser counts requests per authenticated user, or per IP for guests.
+func ByUser(e *core.RequestEvent) string {
+	if e.Auth != nil {
+		return "user:" + e.Auth.Id
+	}
+	return ByIP(e)
+}
+
+// ByBodyField counts requests per value of a JSON body field (e.g. the email
+// a code is sent to), case-insensitively. Requests without it are counted per IP.
+// The body is restored so the handler can still decode it.
+func ByBodyField(field string) KeyFunc {
+	return func(e *core.RequestEvent) string {
+		raw, err := io.ReadAll(io.LimitReader(e.Request.Body, 1<<20))
+		if err != nil {
+			return ByIP(e)
+		}
+		e.Request.Body = io.NopCloser(bytes.NewReader(raw))
+
+		var body map[string]any
+		_ = json.Unmarshal(raw, &body)
+
+		value, _ := body[field].(string)
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			return ByIP(e)
+		}
+		return field + ":" + value
+	}
+}
+
+// 조용해진 키도 남지 않도록 주기적으로 전체 만료 기록을 정리
+const rateLimitPurgeInterval = 10 * time.Minute
+
+// DBRateLimitStore keeps a sliding window log of hits in the rate_limit_hits
+// table, so limits hold across restarts and app instances sharing the database.
+// One store can serve any number of rules with different windows.
+type DBRateLimitStore struct {
+	app core.App
+
+	setupOnce sync.Once
+	setupErr  error
+
+	mu        sync.Mutex
+	lastPurge time.Time
+}
+
+func NewDBRateLimitStore(app core.App) *DBRateLimitStore {
+	return &DBRateLimitStore{app: app}
+}
+
+func (s *DBRateLimitStore) setup() error {
+	s.setupOnce.Do(func() {
+		_, s.setupErr = s.app.DB().NewQuery(`
+			CREATE TABLE IF NOT EXISTS rate_limit_hits (
+				key TEXT NOT NULL,
+				hit_at INTEGER NOT NULL,
+				expires_at INTEGER NOT NULL
+			)
+		`).Execute()
+		if s.setupErr != nil {
+			return
+		}
+		_, s.setupErr = s.app.DB().NewQuery(
+			"CREATE INDEX IF NOT EXISTS idx_rate_limit_hits_key ON rate_limit_hits (key, hit_at)",
+		).Execute()
+		if s.setupErr != nil {
+			return
+		}
+		_, s.setupErr = s.app.DB().NewQuery(
+			"CREATE INDEX IF NOT EXISTS idx_rate_limit_hits_expires ON rate_limit_hits (expires_at)",
+		).Execute()
+	})
+	return s.setupErr
+}
+
+func (s *DBRateLimitStore) Allow(key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	if err := s.setup(); err != nil {
+		return false, 0, err
+	}
+
+	now := time.Now()
+	cutoff := now.Add(-window).UnixMilli()
+	allowed := false
+	var retryAfter time.Duration
+
+	err := s.app.RunInTransaction(func(txApp core.App) error {
+		params := dbx.Params{
+			"key":     key,
+			"cutoff":  cutoff,
+			"now":     now.UnixMilli(),
+			"expires": now.Add(window).UnixMilli(),
+		}
+
+		_, err := txApp.DB().NewQuery(
+			"DELETE FROM rate_limit_hits WHERE key = {:key} AND hit_at <= {:cutoff}",
+		).Bind(params).Execute()
+		if err != nil {
+			return err
+		}
+
+		var stats struct {
+			Count  int   `db:"count"`
+			Oldest int64 `db:"oldest"`
+		}
+		err = txApp.DB().NewQuery(
+			"SELECT COUNT(*) as count, COALESCE(MIN(hit_at), 0) as oldest FROM rate_limit_hits WHERE key = {:key}",
+		).Bind(params).One(&stats)
+		if err != nil {
+			return err
+		}
+
+		if stats.Count >= limit {
+			retryAfter = time.UnixMilli(stats.Oldest).Add(window).Sub(now)
+			return nil
+		}
+
+		_, err = txApp.DB().NewQuery(
+			"INSERT INTO rate_limit_hits (key, hit_at, expires_at) VALUES ({:key}, {:now}, {:expires})",
+		).Bind(params).Execute()
+		if err != nil {
+			return err
+		}
+		allowed = true
+		return nil
+	})
+	if err != nil {
+		return false, 0, err
+	}
+
+	s.purgeExpired()
+
+	return allowed, retryAfter, nil
+}
+
+// purgeExpired deletes every hit that left its window, including those of
+// keys that went quiet. It runs at most once per rateLimitPurgeInterval.
+func (s *DBRateLimitStore) purgeExpired() {
+	s.mu.Lock()
+	if time.Since(s.lastPurge) < rateLimitPurgeInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastPurge = time.Now()
+	s.mu.Unlock()
+
+	_, err := s.app.DB().NewQuery(
+		"DELETE FROM rate_limit_hits WHERE expires_at <= {:now}",
+	).Bind(dbx.Params{"now": time.Now().UnixMilli()}).Execute()
+	if err != nil {
+		log.Printf("[WARN] Failed to purge rate limit hits: %v", err)
+	}
+}
@@ -0,0 +1,231 @@
+// Package notify is the single entry point for creating user notifications.
+// Producers describe what happened as an Event; the dispatcher renders the
+// message, drops duplicates and hands the result to the delivery backend.
+package notify
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/pocketbase/dbx"
+)
+
+// Notification is a rendered event ready for delivery. Template and Data
+// are the raw event, kept so the notification can be rendered again.
+type Notification struct {
+	Recipient  string
+	Type       Type
+	Title      string
+	Message    string
+	Template   string
+	Data       map[string]string
+	TargetID   string
+	TargetType string
+	Actor      string
+	DedupeKey  string
+}
+
+// Backend delivers rendered notifications.
+type Backend interface {
+	Deliver(app core.App, n *Notification) error
+}
+
+var (
+	backendMu sync.RWMutex
+	backend   Backend = RecordBackend{}
+
+	pushListenersMu sync.RWMutex
+	pushListeners   []func(app core.App, n *Notification, notificationId string)
+)
+
+// SetBackend replaces the delivery backend, e.g. to capture notifications in tests.
+func SetBackend(b Backend) {
+	backendMu.Lock()
+	defer backendMu.Unlock()
+	backend = b
+}
+
+func currentBackend() Backend {
+	backendMu.RLock()
+	defer backendMu.RUnlock()
+	return backend
+}
+
+// Dispatch renders the event and delivers it unless it is addressed to its own
+// actor or the same event was already delivered within the dedupe window.
+func Dispatch(app core.App, ev Event) error {
+	if ev.Recipient == "" || ev.Recipient == ev.Actor {
+		return nil
+	}
+
+	if _, ok := lookupTemplate(DefaultLocale, ev.Template); !ok {
+		return fmt.Errorf("unknown notification template %q", ev.Template)
+	}
+
+	dedupeKey := ev.DedupeKey()
+	if ev.DedupeWindow > 0 && isDuplicate(app, dedupeKey, ev.DedupeWindow) {
+		return nil
+	}
+
+	data := map[string]string{}
+	for k, v := range ev.Data {
+		data[k] = v
+	}
+	if _, ok := data["actor"]; !ok && ev.Actor != "" {
+		data["actor"] = actorName(app, ev.Actor)
+	}
+
+	// 수신자의 언어로 렌더링
+	title, message, _ := Render(UserLocale(app, ev.Recipient), ev.Template, data, 1)
+
+	return currentBackend().Deliver(app, &Notification{
+		Recipient:  ev.Recipient,
+		Type:       ev.Type,
+		Title:      title,
+		Message:    message,
+		Template:   ev.Template,
+		Data:       ev.Data,
+		TargetID:   ev.TargetID,
+		TargetType: ev.TargetType,
+		Actor:      ev.Actor,
+		DedupeKey:  dedupeKey,
+	})
+}
+
+// RenderRecord renders a stored notification again from its raw event, in the
+// given locale and with its current actor count. ok is false for notifications
+// created before the event was stored.
+func RenderRecord(app core.App, notification *core.Record, locale string) (title, message string, ok bool) {
+	key := notification.GetString("template")
+	if key == "" {
+		return "", "", false
+	}
+
+	data := map[string]string{}
+	_ = notification.UnmarshalJSONField("event_data", &data)
+	if actorId := notification.GetString("actor"); actorId != "" && data["actor"] == "" {
+		data["actor"] = actorName(app, actorId)
+	}
+
+	return Render(locale, key, data, max(notification.GetInt("actor_count"), 1))
+}
+
+// Send dispatches the event and logs failures. Meant for fire-and-forget
+// producers such as record hooks and goroutines started by handlers.
+func Send(app core.App, ev Event) {
+	if err := Dispatch(app, ev); err != nil {
+		log.Printf("[Notification] Failed to dispatch %s to %s: %v", ev.Template, ev.Recipient, err)
+	}
+}
+
+// OnPush registers fn to send the push of a notification the backend did not
+// store as a new record (folded into a group, or muted in-app), so no record
+// hook fires for it. notificationId is empty when the type is muted in-app.
+func OnPush(fn func(app core.App, n *Notification, notificationId string)) {
+	pushListenersMu.Lock()
+	defer pushListenersMu.Unlock()
+	pushListeners = append(pushListeners, fn)
+}
+
+func pushed(app core.App, n *Notification, notificationId string) {
+	pushListenersMu.RLock()
+	listeners := pushListeners
+	pushListenersMu.RUnlock()
+
+	for _, fn := range listeners {
+		fn(app, n, notificationId)
+	}
+}
+
+func isDuplicate(app core.App, dedupeKey string, window time.Duration) bool {
+	since := types.NowDateTime().Add(-window)
+
+	var count int
+	_ = app.DB().NewQuery(
+		"SELECT COUNT(*) FROM notifications WHERE dedupe_key = {:key} AND created >= {:since}",
+	).Bind(dbx.Params{"key": dedupeKey, "since": since.String()}).Row(&count)
+
+	return count > 0
+}
+
+// actorName returns the actor's display name; empty names are rendered
+// with the anonymous name of the recipient's locale.
+func actorName(app core.App, actorId string) string {
+	actor, err := app.FindRecordById("users", actorId)
+	if err != nil {
+		return ""
+	}
+	return actor.GetString("name")
+}
+
+// RecordBackend stores notifications in the notifications collection. The
+// recipient's in-app preference and grouping are applied before anything is
+// saved; pushes of new records are sent by the record hooks.
+type RecordBackend struct{}
+
+func (RecordBackend) Deliver(app core.App, n *Notification) error {
+	// 앱 내 알림을 끈 유형은 저장하지 않고 푸시만 전송 (푸시 설정은 수신 측에서 확인)
+	if !Allowed(app, n.Recipient, string(n.Type), ChannelInApp) {
+		pushed(app, n, "")
+		return nil
+	}
+
+	if !isGrouped(n) {
+		return saveNotification(app, n)
+	}
+
+	var group *core.Record
+	var pushGroup bool
+	err := app.RunInTransaction(func(txApp core.App) error {
+		var err error
+		group, pushGroup, err = foldIntoGroup(txApp, n)
+		if err != nil || group != nil {
+			return err
+		}
+		return saveNotification(txApp, n)
+	})
+	if err != nil {
+		return err
+	}
+
+	if pushGroup {
+		grouped := *n
+		grouped.Title, grouped.Message = group.GetString("title"), group.GetString("message")
+		pushed(app, &grouped, group.Id)
+	}
+	return nil
+}
+
+func saveNotification(app core.App, n *Notification) error {
+	collection, err := app.FindCachedCollectionByNameOrId("notifications")
+	if err != nil {
+		return err
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("user", n.Recipient)
+	record.Set("type", string(n.Type))
+	record.Set("title", n.Title)
+	record.Set("message", n.Message)
+	record.Set("template", n.Template)
+	record.Set("event_data", n.Data)
+	record.Set("target_id", n.TargetID)
+	record.Set("target_type", n.TargetType)
+	record.Set("is_read", false)
+	record.Set("actor", n.Actor)
+	record.Set("dedupe_key", n.DedupeKey)
+
+	// 새 묶음 시작
+	if isGrouped(n) {
+		record.Set("actor_count", 1)
+		record.Set("actor_ids", []string{n.Actor})
+		record.Set("recent_actors", []string{n.Actor})
+		record.Set("last_pushed_at", types.NowDateTime())
+	}
+
+	return app.Save(record)
+}
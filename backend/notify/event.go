@@ -0,0 +1,116 @@
+package notify
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Type is the notification type stored in the notifications collection.
+type Type string
+
+const (
+	TypeLike    Type = "like"
+	TypeComment Type = "comment"
+	TypeFollow  Type = "follow"
+	TypeAnswer  Type = "answer"
+	TypeMention Type = "mention"
+	TypeSystem  Type = "system"
+)
+
+// 같은 이벤트가 이 기간 안에 다시 발생하면 알림을 만들지 않음
+const defaultDedupeWindow = 24 * time.Hour
+
+// Event describes something a user should be notified about.
+// Template selects the title/message; Data fills its placeholders.
+// Events with a zero DedupeWindow are never treated as duplicates.
+type Event struct {
+	Type         Type
+	Template     string
+	Recipient    string
+	Actor        string
+	TargetID     string
+	TargetType   string
+	Data         map[string]string
+	DedupeWindow time.Duration
+}
+
+// DedupeKey identifies repeats of the same event, e.g. a like that was
+// removed and added again, or a follow toggled off and on. Data is part of
+// the key, so events differing only in their data (e.g. two badges awarded
+// to the same user) are not duplicates.
+func (ev Event) DedupeKey() string {
+	parts := []string{ev.Template, ev.Recipient, ev.Actor, ev.TargetType, ev.TargetID}
+	for _, k := range slices.Sorted(maps.Keys(ev.Data)) {
+		parts = append(parts, k+"="+ev.Data[k])
+	}
+	return strings.Join(parts, ":")
+}
+
+// Like is sent to the author of liked content. contentType and contentId are
+// the liked content (post, comment, answer); the target is where the client
+// navigates. The content id is kept in Data so likes on two comments of the
+// same post are not duplicates.
+func Like(recipient, actor, contentType, contentId, targetId, targetType string) Event {
+	return Event{
+		Type:         TypeLike,
+		Template:     "like." + contentType,
+		Recipient:    recipient,
+		Actor:        actor,
+		TargetID:     targetId,
+		TargetType:   targetType,
+		Data:         map[string]string{"content_id": contentId},
+		DedupeWindow: defaultDedupeWindow,
+	}
+}
+
+// Comment is sent to the post author when someone comments on the post.
+func Comment(recipient, actor, postId string) Event {
+	return Event{
+		Type:       TypeComment,
+		Template:   "comment.post",
+		Recipient:  recipient,
+		Actor:      actor,
+		TargetID:   postId,
+		TargetType: "post",
+	}
+}
+
+// Answer is sent to the question author when someone answers the question.
+func Answer(recipient, actor, questionId string) Event {
+	return Event{
+		Type:       TypeAnswer,
+		Template:   "answer.question",
+		Recipient:  recipient,
+		Actor:      actor,
+		TargetID:   questionId,
+		TargetType: "question",
+	}
+}
+
+// Follow is sent to the followed user.
+func Follow(recipient, actor string) Event {
+	return Event{
+		Type:         TypeFollow,
+		Template:     "follow.user",
+		Recipient:    recipient,
+		Actor:        actor,
+		TargetID:     actor,
+		TargetType:   "user",
+		DedupeWindow: defaultDedupeWindow,
+	}
+}
+
+// System is a notification without an actor, e.g. badges or finished polls.
+func System(template, recipient, targetId, targetType string, data map[string]string) Event {
+	return Event{
+		Type:         TypeSystem,
+		Template:     template,
+		Recipient:    recipient,
+		TargetID:     targetId,
+		TargetType:   targetType,
+		Data:         data,
+		DedupeWindow: defaultDedupeWindow,
+	}
+}
@@ -0,0 +1,100 @@
+package notify
+
+import (
+	"slices"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/pocketbase/dbx"
+)
+
+const (
+	// 같은 묶음 알림의 푸시는 이 간격 안에서 한 번만 전송
+	groupPushInterval = 10 * time.Minute
+	// 알림에 노출할 최근 행위자 수
+	maxRecentActors = 3
+)
+
+// groupedTypes are the notification types grouped by (user, type, template,
+// target) while unread. The template keeps likes on a post apart from likes
+// on its comments, which share the post as target. Follow and system notifications always stay separate.
+var groupedTypes = []Type{TypeLike, TypeComment, TypeAnswer}
+
+func isGrouped(n *Notification) bool {
+	return n.Actor != "" && slices.Contains(groupedTypes, n.Type)
+}
+
+// foldIntoGroup adds the actor of n to the unread notification of the same
+// group, so the user sees one evolving row instead of many. It returns the
+// updated group, or nil when there is none and n must be stored as a new
+// record. push reports whether the group is due for a push.
+// A repeated actor (e.g. like, unlike, like again) leaves the group untouched.
+func foldIntoGroup(app core.App, n *Notification) (group *core.Record, push bool, err error) {
+	existing, err := app.FindFirstRecordByFilter("notifications",
+		"user = {:user} && type = {:type} && template = {:template} && target_id = {:tid} && target_type = {:tt} && is_read = false",
+		dbx.Params{
+			"user":     n.Recipient,
+			"type":     string(n.Type),
+			"template": n.Template,
+			"tid":      n.TargetID,
+			"tt":       n.TargetType,
+		},
+	)
+	if err != nil {
+		return nil, false, nil
+	}
+
+	actorIds := groupActorIds(existing)
+	if slices.Contains(actorIds, n.Actor) {
+		return existing, false, nil
+	}
+	actorIds = append(actorIds, n.Actor)
+
+	existing.Set("actor", n.Actor)
+	existing.Set("actor_ids", actorIds)
+	existing.Set("actor_count", len(actorIds))
+	existing.Set("recent_actors", recentActorIds(actorIds))
+	existing.Set("event_data", n.Data)
+
+	// "A님 외 N명이 ..." 형태로 수신자의 언어에 맞게 다시 렌더링
+	title, message, ok := RenderRecord(app, existing, UserLocale(app, n.Recipient))
+	if !ok {
+		title, message = n.Title, n.Message
+	}
+	existing.Set("title", title)
+	existing.Set("message", message)
+
+	push = existing.GetDateTime("last_pushed_at").Time().Before(time.Now().Add(-groupPushInterval))
+	if push {
+		existing.Set("last_pushed_at", types.NowDateTime())
+	}
+
+	if err := app.Save(existing); err != nil {
+		return nil, false, err
+	}
+	return existing, push, nil
+}
+
+// groupActorIds returns the distinct actors of a notification group in the
+// order they acted. Notifications created before grouping only have actor.
+func groupActorIds(notification *core.Record) []string {
+	var actorIds []string
+	_ = notification.UnmarshalJSONField("actor_ids", &actorIds)
+	if len(actorIds) == 0 {
+		if actorId := notification.GetString("actor"); actorId != "" {
+			actorIds = []string{actorId}
+		}
+	}
+	return actorIds
+}
+
+// recentActorIds returns up to maxRecentActors actors, newest first.
+func recentActorIds(actorIds []string) []string {
+	recent := slices.Clone(actorIds)
+	slices.Reverse(recent)
+	if len(recent) > maxRecentActors {
+		recent = recent[:maxRecentActors]
+	}
+	return recent
+}
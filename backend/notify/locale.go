@@ -0,0 +1,49 @@
+package notify
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const (
+	LocaleKo = "ko"
+	LocaleEn = "en"
+
+	DefaultLocale = LocaleKo
+)
+
+// Locales are the languages notifications and emails are rendered in.
+var Locales = []string{LocaleKo, LocaleEn}
+
+// NormalizeLocale maps a locale or Accept-Language value ("en-US",
+// "en-US,en;q=0.9,ko;q=0.8") to a supported locale, or DefaultLocale.
+func NormalizeLocale(value string) string {
+	for _, part := range strings.Split(value, ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		lang, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		lang, _, _ = strings.Cut(lang, "_")
+		if slices.Contains(Locales, lang) {
+			return lang
+		}
+	}
+	return DefaultLocale
+}
+
+// LocaleOf returns the locale stored on a user record.
+func LocaleOf(user *core.Record) string {
+	if user == nil {
+		return DefaultLocale
+	}
+	return NormalizeLocale(user.GetString("locale"))
+}
+
+// UserLocale returns the locale of the user with the given id.
+func UserLocale(app core.App, userId string) string {
+	user, err := app.FindRecordById("users", userId)
+	if err != nil {
+		return DefaultLocale
+	}
+	return LocaleOf(user)
+}
@@ -0,0 +1,29 @@
+package notify
+
+import (
+	"slices"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/dbx"
+)
+
+// Delivery channels a user can mute per notification type.
+const (
+	ChannelInApp = "in_app"
+	ChannelPush  = "push"
+)
+
+// DefaultTimezone is the quiet hours timezone of users who did not pick one.
+const DefaultTimezone = "Asia/Seoul"
+
+// Allowed reports whether the user wants notifications of the given type on
+// the channel. Users without preferences receive everything.
+func Allowed(app core.App, userId, notifType, channel string) bool {
+	prefs, err := app.FindFirstRecordByFilter("notification_preferences",
+		"user = {:user}", dbx.Params{"user": userId})
+	if err != nil {
+		return true
+	}
+
+	return !slices.Contains(prefs.GetStringSlice(channel+"_muted"), notifType)
+}
@@ -0,0 +1,125 @@
+package notify
+
+import (
+	"strconv"
+	"strings"
+)
+
+type template struct {
+	Title   string
+	Message string
+	// Group is used instead of Message when several actors are folded into
+	// one notification; {others} is the number of actors besides {actor}.
+	Group string
+}
+
+// templates maps a locale and template key to its title and message.
+// {actor} is filled with the actor's name, other placeholders from Event.Data.
+var templates = map[string]map[string]template{
+	LocaleKo: {
+		"like.post":    {"좋아요", "{actor}님이 회원님의 게시글을 좋아합니다.", "{actor}님 외 {others}명이 회원님의 게시글을 좋아합니다."},
+		"like.comment": {"좋아요", "{actor}님이 회원님의 댓글을 좋아합니다.", "{actor}님 외 {others}명이 회원님의 댓글을 좋아합니다."},
+		"like.answer":  {"좋아요", "{actor}님이 회원님의 답변을 좋아합니다.", "{actor}님 외 {others}명이 회원님의 답변을 좋아합니다."},
+
+		"comment.post":    {"새 댓글", "회원님의 게시글에 새 댓글이 달렸습니다.", "{actor}님 외 {others}명이 회원님의 게시글에 댓글을 남겼습니다."},
+		"answer.question": {"새 답변", "회원님의 질문에 새 답변이 달렸습니다.", "{actor}님 외 {others}명이 회원님의 질문에 답변을 남겼습니다."},
+		"follow.user":     {"새 팔로워", "{actor}님이 회원님을 팔로우합니다.", ""},
+
+		"badge.awarded":     {"배지 획득", "'{badge}' 배지를 획득했습니다.", ""},
+		"poll.closed":       {"투표 종료", "투표가 종료되었습니다. 1위: {label} ({votes}/{total}표)", ""},
+		"poll.closed_empty": {"투표 종료", "투표가 종료되었습니다. 참여한 사람이 없습니다.", ""},
+		"publish.post":      {"예약 게시 완료", "예약한 게시글이 게시되었습니다.", ""},
+		"publish.question":  {"예약 게시 완료", "예약한 질문이 게시되었습니다.", ""},
+	},
+	LocaleEn: {
+		"like.post":    {"New like", "{actor} liked your post.", "{actor} and {others} others liked your post."},
+		"like.comment": {"New like", "{actor} liked your comment.", "{actor} and {others} others liked your comment."},
+		"like.answer":  {"New like", "{actor} liked your answer.", "{actor} and {others} others liked your answer."},
+
+		"comment.post":    {"New comment", "Someone commented on your post.", "{actor} and {others} others commented on your post."},
+		"answer.question": {"New answer", "Your question has a new answer.", "{actor} and {others} others answered your question."},
+		"follow.user":     {"New follower", "{actor} started following you.", ""},
+
+		"badge.awarded":     {"Badge earned", "You earned the '{badge}' badge.", ""},
+		"poll.closed":       {"Poll closed", "The poll has ended. Winner: {label} ({votes}/{total} votes)", ""},
+		"poll.closed_empty": {"Poll closed", "The poll has ended with no votes.", ""},
+		"publish.post":      {"Scheduled post published", "Your scheduled post has been published.", ""},
+		"publish.question":  {"Scheduled question published", "Your scheduled question has been published.", ""},
+	},
+}
+
+// badgeNames are the badge names per locale. Badge events carry the badge
+// code in {badge}, which Render replaces with the name.
+var badgeNames = map[string]map[string]string{
+	LocaleKo: {
+		"first_accepted_answer": "첫 채택",
+		"likes_100":             "사랑받는 물생활러",
+		"record_streak_30":      "꾸준한 기록",
+		"aquariums_5":           "어항 부자",
+	},
+	LocaleEn: {
+		"first_accepted_answer": "First accepted answer",
+		"likes_100":             "Community favorite",
+		"record_streak_30":      "Steady logger",
+		"aquariums_5":           "Aquarium collector",
+	},
+}
+
+// BadgeName returns the name of a badge in the locale. Unknown codes, such as
+// names stored by older notifications, are returned unchanged.
+func BadgeName(locale, code string) string {
+	if name, ok := badgeNames[NormalizeLocale(locale)][code]; ok {
+		return name
+	}
+	if name, ok := badgeNames[DefaultLocale][code]; ok {
+		return name
+	}
+	return code
+}
+
+// 이름이 없는 행위자를 표시할 때 사용하는 이름
+var anonymousActor = map[string]string{
+	LocaleKo: "회원",
+	LocaleEn: "Someone",
+}
+
+func lookupTemplate(locale, key string) (template, bool) {
+	if t, ok := templates[locale][key]; ok {
+		return t, true
+	}
+	t, ok := templates[DefaultLocale][key]
+	return t, ok
+}
+
+// Render renders a notification template in the given locale. actorCount > 1
+// selects the grouped message when the template has one.
+func Render(locale, key string, data map[string]string, actorCount int) (title, message string, ok bool) {
+	t, ok := lookupTemplate(locale, key)
+	if !ok {
+		return "", "", false
+	}
+
+	values := make(map[string]string, len(data)+2)
+	for k, v := range data {
+		values[k] = v
+	}
+	if values["actor"] == "" {
+		values["actor"] = anonymousActor[NormalizeLocale(locale)]
+	}
+	values["others"] = strconv.Itoa(actorCount - 1)
+	if code, ok := values["badge"]; ok {
+		values["badge"] = BadgeName(locale, code)
+	}
+
+	pairs := make([]string, 0, len(values)*2)
+	for k, v := range values {
+		pairs = append(pairs, "{"+k+"}", v)
+	}
+	r := strings.NewReplacer(pairs...)
+
+	message = t.Message
+	if actorCount > 1 && t.Group != "" {
+		message = t.Group
+	}
+	return r.Replace(t.Title), r.Replace(message), true
+}
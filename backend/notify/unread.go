@@ -0,0 +1,48 @@
+package notify
+
+import (
+	"sync"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/dbx"
+)
+
+var (
+	unreadListenersMu sync.RWMutex
+	unreadListeners   []func(app core.App, userId string)
+)
+
+// UnreadCount returns the number of unread notifications of the user.
+func UnreadCount(app core.App, userId string) int {
+	count, _ := CountUnread(app, userId)
+	return count
+}
+
+// CountUnread is UnreadCount for callers that need to surface query errors.
+func CountUnread(app core.App, userId string) (int, error) {
+	var count int
+	err := app.DB().NewQuery(
+		"SELECT COUNT(*) FROM notifications WHERE user = {:userId} AND is_read = 0",
+	).Bind(dbx.Params{"userId": userId}).Row(&count)
+	return count, err
+}
+
+// OnUnreadChanged registers fn to be called whenever notifications of a user
+// are marked as read outside of the record hooks (e.g. bulk SQL updates).
+func OnUnreadChanged(fn func(app core.App, userId string)) {
+	unreadListenersMu.Lock()
+	defer unreadListenersMu.Unlock()
+	unreadListeners = append(unreadListeners, fn)
+}
+
+// UnreadChanged notifies the registered listeners that the unread count of
+// the user changed.
+func UnreadChanged(app core.App, userId string) {
+	unreadListenersMu.RLock()
+	listeners := unreadListeners
+	unreadListenersMu.RUnlock()
+
+	for _, fn := range listeners {
+		fn(app, userId)
+	}
+}
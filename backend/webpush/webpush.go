@@ -0,0 +1,99 @@
+package webpush
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateAddress is returned when a push endpoint resolves to an address
+// that is not publicly routable.
+var ErrPrivateAddress = errors.New("web push endpoint resolves to a non-public address")
+
+// Subscription is the PushSubscription JSON a browser returns from
+// pushManager.subscribe(). It is stored as the token of a web device.
+type Subscription struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+// 공인 주소가 아닌 대역 (net.IP 메서드로 판별되지 않는 것만)
+var nonPublicNets = []*net.IPNet{
+	mustCIDR("0.0.0.0/8"),     // "this" network
+	mustCIDR("100.64.0.0/10"), // CGNAT
+	mustCIDR("192.0.0.0/24"),  // IETF protocol assignments
+	mustCIDR("198.18.0.0/15"), // benchmarking
+	mustCIDR("64:ff9b::/96"),  // NAT64 (내부 IPv4로 변환될 수 있음)
+}
+
+func mustCIDR(cidr string) *net.IPNet {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return ipNet
+}
+
+// IsPublicIP reports whether ip is a publicly routable unicast address, i.e.
+// not loopback, private (including fc00::/7), link-local, multicast,
+// unspecified or CGNAT.
+func IsPublicIP(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, ipNet := range nonPublicNets {
+		if ipNet.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// AllowPrivateEndpoints reports whether WEBPUSH_ALLOW_PRIVATE_ENDPOINTS=true,
+// which allows http and private hosts for development against a local push service.
+func AllowPrivateEndpoints() bool {
+	return os.Getenv("WEBPUSH_ALLOW_PRIVATE_ENDPOINTS") == "true"
+}
+
+// NewClient returns the HTTP client pushes are sent with. It does not follow
+// redirects and refuses to connect to non-public addresses, so an endpoint
+// that redirects or re-resolves after registration cannot reach internal hosts.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if !AllowPrivateEndpoints() {
+		// DNS 조회 이후 실제로 접속하는 주소를 검사
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if !IsPublicIP(net.ParseIP(host)) {
+				return ErrPrivateAddress
+			}
+			return nil
+		}
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+			MaxIdleConnsPerHost: 10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -1,3 +1,5 @@
+import 'dart:ui' show PlatformDispatcher;
+
 import 'package:pocketbase/pocketbase.dart';
 import 'package:url_launcher/url_launcher.dart';
 import 'pocketbase_service.dart';
@@ -22,6 +24,36 @@ class AuthService {
   /// 로그인 상태 확인
   bool get isLoggedIn => _pb.authStore.isValid;
 
+  /// 서버가 알림/이메일을 보낼 수 있는 언어
+  static const List<String> _supportedLocales = ['ko', 'en'];
+
+  /// 기기 언어 (서버가 지원하지 않는 언어면 null)
+  String? get _deviceLocale {
+    final code = PlatformDispatcher.instance.locale.languageCode;
+    return _supportedLocales.contains(code) ? code : null;
+  }
+
+  /// 기기 언어를 알림 수신 언어로 저장
+  ///
+  /// 실패해도 로그인 흐름에는 영향을 주지 않음
+  Future<void> syncLocale() async {
+    final locale = _deviceLocale;
+    final user = currentUser;
+    if (locale == null || user == null) return;
+    if (user.getStringValue('locale') == locale) return;
+
+    try {
+      await _pb.send(
+        '/api/notifications/preferences',
+        method: 'PATCH',
+        body: {'locale': locale},
+      );
+      AppLogger.auth('Locale synced: $locale');
+    } catch (e) {
+      AppLogger.auth('Failed to sync locale: $e', isError: true);
+    }
+  }
+
   /// 이메일/비밀번호로 로그인
   Future<RecordModel> loginWithEmail({
     required String email,
@@ -32,6 +64,7 @@ class AuthService {
           .collection('users')
           .authWithPassword(email, password);
       AppLogger.auth('Login successful: ${authData.record.id}');
+      await syncLocale();
       return authData.record;
     } on ClientException catch (e) {
       AppLogger.auth('Login failed: $e', isError: true);
@@ -74,6 +107,7 @@ class AuthService {
               'password': password,
               'passwordConfirm': passwordConfirm,
               'name': name,
+              if (_deviceLocale != null) 'locale': _deviceLocale,
             },
           );
       AppLogger.auth('Sign up successful: ${record.id}');
@@ -306,6 +340,7 @@ class AuthService {
 
       // 로그인 성공 후 토큰 저장
       await PocketBaseService.instance.onLoginSuccess();
+      await syncLocale();
 
       return authData.record;
     } on ClientException catch (e) {
@@ -321,7 +356,7 @@ class AuthService {
     }
   }
 
-  /// 4자리 인증 코드 요청 (커스텀 API)
+  /// 6자리 인증 코드 요청 (커스텀 API)
   Future<bool> sendVerificationCode(String email) async {
     try {
       final response = await _pb.send(
@@ -347,7 +382,7 @@ class AuthService {
     }
   }
 
-  /// 4자리 인증 코드 검증 (커스텀 API)
+  /// 6자리 인증 코드 검증 (커스텀 API)
   Future<bool> verifyCode(String email, String code) async {
     try {
       final response = await _pb.send(
@@ -22,6 +22,16 @@ class CommunityService {
 
   String? get _currentUserId => _pb.authStore.record?.id;
 
+  /// 게시된 글만 조회하는 필터 (작성자 본인에게는 임시저장/예약 글도 조회 권한이 있음)
+  static const String _publishedFilter =
+      "status != 'draft' && status != 'scheduled'";
+
+  /// 목록 필터에 게시 상태 조건을 추가
+  String _withPublished(String? filter) {
+    if (filter == null || filter.isEmpty) return _publishedFilter;
+    return '($filter) && $_publishedFilter';
+  }
+
   // ==================== Questions (Q&A) ====================
 
   /// 질문 목록 조회
@@ -37,7 +47,7 @@ class CommunityService {
           .getList(
             page: page,
             perPage: perPage,
-            filter: filter?.isNotEmpty == true ? filter : null,
+            filter: _withPublished(filter),
             sort: sort?.isNotEmpty == true ? sort : null,
             expand: 'attached_records',
           );
@@ -185,7 +195,7 @@ class CommunityService {
           .getList(
             page: page,
             perPage: perPage,
-            filter: filter?.isNotEmpty == true ? filter : null,
+            filter: _withPublished(filter),
             sort: sort?.isNotEmpty == true ? sort : null,
           );
 
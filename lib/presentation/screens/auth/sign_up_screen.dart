@@ -265,7 +265,7 @@ class _SignUpScreenState extends State<SignUpScreen>
   }
 
   Future<void> _verifyCode() async {
-    if (_verificationCodeController.text.length != 4) return;
+    if (_verificationCodeController.text.length != 6) return;
 
     setState(() => _isLoading = true);
     try {
@@ -522,12 +522,12 @@ class _SignUpScreenState extends State<SignUpScreen>
                 child: _buildTextField(
                   controller: _verificationCodeController,
                   focusNode: _verificationFocusNode,
-                  hintText: '인증번호 4자리',
+                  hintText: '인증번호 6자리',
                   keyboardType: TextInputType.number,
-                  maxLength: 4,
+                  maxLength: 6,
                   onChanged: (value) {
                     setState(() {});
-                    if (value.length == 4) {
+                    if (value.length == 6) {
                       _verifyCode();
                     }
                   },